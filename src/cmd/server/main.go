@@ -11,11 +11,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/chrote/server/internal/api"
+	"github.com/chrote/server/internal/core"
 	"github.com/chrote/server/internal/dashboard"
 	"github.com/chrote/server/internal/proxy"
 )
@@ -31,9 +33,23 @@ type Config struct {
 	APIAuthToken  string
 	CORSOrigins   []string
 	StartTtyd     bool
+	// TerminalBackend serves /terminal/ from a ttyd child process ("ttyd")
+	// or from in-process ptys ("native")
+	TerminalBackend string
+}
+
+// terminal is the /terminal/ backend
+type terminal interface {
+	RegisterRoutes(mux *http.ServeMux)
+	Stop() error
 }
 
 func main() {
+	// tmux pipe-pane runs the server binary to record sessions (see api.StartRecording)
+	if len(os.Args) > 1 && os.Args[1] == "record-cast" {
+		os.Exit(recordCast(os.Args[2:]))
+	}
+
 	// Parse flags
 	config := Config{}
 	flag.IntVar(&config.Port, "port", 8080, "Server port")
@@ -41,6 +57,7 @@ func main() {
 	flag.IntVar(&config.BvTtydPort, "bv-ttyd-port", 7682, "bv (beads viewer) ttyd port")
 	flag.StringVar(&config.APIAuthToken, "auth-token", "", "API authentication token")
 	flag.BoolVar(&config.StartTtyd, "start-ttyd", true, "Start ttyd child process")
+	flag.StringVar(&config.TerminalBackend, "terminal-backend", "ttyd", "Terminal backend: ttyd or native")
 	flag.Parse()
 
 	// Environment overrides
@@ -53,6 +70,12 @@ func main() {
 	if port := os.Getenv("BV_TTYD_PORT"); port != "" {
 		fmt.Sscanf(port, "%d", &config.BvTtydPort)
 	}
+	if backend := os.Getenv("CHROTE_TERMINAL_BACKEND"); backend != "" {
+		config.TerminalBackend = backend
+	}
+	if config.TerminalBackend != "ttyd" && config.TerminalBackend != "native" {
+		log.Fatalf("Invalid terminal backend %q: use ttyd or native", config.TerminalBackend)
+	}
 	if token := os.Getenv("API_AUTH_TOKEN"); token != "" {
 		config.APIAuthToken = token
 	}
@@ -69,6 +92,7 @@ func main() {
 	// Register API handlers
 	tmuxHandler := api.NewTmuxHandler()
 	tmuxHandler.RegisterRoutes(mux)
+	tmuxHandler.Start()
 
 	beadsHandler := api.NewBeadsHandler()
 	beadsHandler.RegisterRoutes(mux)
@@ -82,9 +106,16 @@ func main() {
 	chatHandler := api.NewChatHandler()
 	chatHandler.RegisterRoutes(mux)
 
-	// Create terminal proxy
-	terminalProxy := proxy.NewTerminalProxy(config.TtydPort)
-	terminalProxy.RegisterRoutes(mux)
+	// Create terminal: the native backend needs no ttyd child process
+	var terminalProxy *proxy.TerminalProxy
+	var term terminal
+	if config.TerminalBackend == "native" {
+		term = proxy.NewNativeTerminal()
+	} else {
+		terminalProxy = proxy.NewTerminalProxy(config.TtydPort)
+		term = terminalProxy
+	}
+	term.RegisterRoutes(mux)
 
 	// Create BV terminal proxy (beads viewer)
 	bvTerminalProxy := proxy.NewBvTerminalProxy(config.BvTtydPort)
@@ -108,11 +139,15 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
-	// Start ttyd if configured
-	if config.StartTtyd {
+	// Start ttyd if configured; its supervisor restarts it whenever it exits
+	if config.StartTtyd && terminalProxy != nil {
+		healthHandler.AddComponent("ttyd", func() (bool, interface{}) {
+			status := terminalProxy.Status()
+			return status.State == proxy.StateRunning, status
+		})
 		if err := terminalProxy.Start(); err != nil {
 			log.Printf("Warning: failed to start ttyd: %v", err)
-			log.Printf("Terminal functionality will not be available")
+			log.Printf("Terminal functionality will not be available until it starts")
 		}
 	}
 
@@ -124,6 +159,7 @@ func main() {
 		log.Printf("CHROTE v%s starting on port %d", Version, config.Port)
 		log.Printf("Dashboard: http://localhost:%d/", config.Port)
 		log.Printf("API: http://localhost:%d/api/", config.Port)
+		log.Printf("Events: http://localhost:%d/api/events", config.Port)
 		log.Printf("Chat: http://localhost:%d/api/chat/", config.Port)
 		log.Printf("Files: http://localhost:%d/api/files/", config.Port)
 		log.Printf("Terminal: http://localhost:%d/terminal/", config.Port)
@@ -138,11 +174,12 @@ func main() {
 	<-done
 	log.Println("Shutting down server...")
 
-	// Stop ttyd processes
-	if config.StartTtyd {
-		terminalProxy.Stop()
+	// Stop terminals and ttyd processes
+	if config.StartTtyd || terminalProxy == nil {
+		term.Stop()
 	}
 	bvTerminalProxy.Stop()
+	tmuxHandler.Stop()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -155,6 +192,29 @@ func main() {
 	log.Println("Server stopped")
 }
 
+// recordCast records pane output from stdin to an asciicast file
+// Usage: record-cast <file> <width> <height> [title]
+func recordCast(args []string) int {
+	if len(args) < 3 {
+		fmt.Fprintln(os.Stderr, "usage: record-cast <file> <width> <height> [title]")
+		return 2
+	}
+	header := core.CastHeader{
+		Env: map[string]string{"TERM": "xterm-256color"},
+	}
+	header.Width, _ = strconv.Atoi(args[1])
+	header.Height, _ = strconv.Atoi(args[2])
+	if len(args) > 3 {
+		header.Title = args[3]
+	}
+
+	if err := core.RecordCast(os.Stdin, args[0], header, time.Now); err != nil {
+		fmt.Fprintf(os.Stderr, "record-cast: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
 // corsMiddleware adds CORS headers
 func corsMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -262,6 +322,11 @@ func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return nil, nil, fmt.Errorf("underlying ResponseWriter does not support Hijack")
 }
 
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Flush implements http.Flusher interface
 func (rw *responseWriter) Flush() {
 	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
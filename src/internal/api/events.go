@@ -0,0 +1,178 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session event types published on the tmux event stream
+const (
+	EventSessionCreated        = "session-created"
+	EventSessionKilled         = "session-killed"
+	EventSessionRenamed        = "session-renamed"
+	EventSessionAttached       = "session-attached"
+	EventSessionDetached       = "session-detached"
+	EventSessionWindowsChanged = "session-windows-changed"
+	EventSessionState          = "session-state" // Agent state changed, see sessionMonitor
+	EventWatcherFired          = "watcher-fired" // An output watcher matched, see watcherEngine
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment to keep proxies from closing it
+const sseKeepAlive = 15 * time.Second
+
+// Event is a server-sent notification about a change in server state
+type Event struct {
+	Type      string                 `json:"type"`
+	Session   string                 `json:"session,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Timestamp string                 `json:"timestamp"`
+}
+
+// NewEvent creates an event stamped with the current time
+func NewEvent(eventType, session string, data map[string]interface{}) Event {
+	return Event{
+		Type:      eventType,
+		Session:   session,
+		Data:      data,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// EventHub fans published events out to any number of subscribers
+type EventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewEventHub creates a new EventHub
+func NewEventHub() *EventHub {
+	return &EventHub{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber and returns its channel and an unsubscribe func
+func (hub *EventHub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 64)
+
+	hub.mu.Lock()
+	hub.subscribers[ch] = struct{}{}
+	hub.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			hub.mu.Lock()
+			delete(hub.subscribers, ch)
+			hub.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers an event to every subscriber
+// Subscribers that are not keeping up miss the event rather than blocking the publisher
+func (hub *EventHub) Publish(event Event) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for ch := range hub.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscribers
+func (hub *EventHub) SubscriberCount() int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	return len(hub.subscribers)
+}
+
+// writeSSEEvent writes one event in text/event-stream format
+func writeSSEEvent(w http.ResponseWriter, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload)
+	return err
+}
+
+// serveEventStream streams events to the client as Server-Sent Events until it disconnects
+// initial is written first (e.g. a snapshot); keep filters events, nil keeps everything
+func serveEventStream(w http.ResponseWriter, r *http.Request, events <-chan Event, initial []Event, keep func(Event) bool) {
+	controller := http.NewResponseController(w)
+	// Event streams outlive the server's write timeout
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range initial {
+		if writeSSEEvent(w, event.Type, event) != nil {
+			return
+		}
+	}
+	if controller.Flush() != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if keep != nil && !keep(event) {
+				continue
+			}
+			if writeSSEEvent(w, event.Type, event) != nil {
+				return
+			}
+		}
+		if controller.Flush() != nil {
+			return
+		}
+	}
+}
+
+// eventFilter builds a filter from the types and session query parameters
+func eventFilter(r *http.Request) func(Event) bool {
+	session := r.URL.Query().Get("session")
+	types := make(map[string]bool)
+	for _, t := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types[t] = true
+		}
+	}
+
+	if session == "" && len(types) == 0 {
+		return nil
+	}
+	return func(e Event) bool {
+		if session != "" && e.Session != session {
+			return false
+		}
+		return len(types) == 0 || types[e.Type]
+	}
+}
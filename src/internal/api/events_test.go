@@ -0,0 +1,104 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventHub_PublishSubscribe(t *testing.T) {
+	hub := NewEventHub()
+
+	events, unsubscribe := hub.Subscribe()
+	if hub.SubscriberCount() != 1 {
+		t.Fatalf("SubscriberCount() = %d, expected 1", hub.SubscriberCount())
+	}
+
+	hub.Publish(NewEvent(EventSessionCreated, "main", nil))
+
+	select {
+	case e := <-events:
+		if e.Type != EventSessionCreated || e.Session != "main" {
+			t.Errorf("Received %+v, expected session-created for main", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+
+	unsubscribe()
+	unsubscribe() // Must be safe to call twice
+	if hub.SubscriberCount() != 0 {
+		t.Errorf("SubscriberCount() = %d after unsubscribe, expected 0", hub.SubscriberCount())
+	}
+
+	// Publishing with no subscribers must not block or panic
+	hub.Publish(NewEvent(EventSessionKilled, "main", nil))
+}
+
+func TestEventHub_SlowSubscriberDoesNotBlock(t *testing.T) {
+	hub := NewEventHub()
+	_, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			hub.Publish(NewEvent(EventSessionCreated, "s", nil))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish blocked on a subscriber that never reads")
+	}
+}
+
+func TestServeEventStream(t *testing.T) {
+	hub := NewEventHub()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events, unsubscribe := hub.Subscribe()
+		defer unsubscribe()
+		serveEventStream(w, r, events, []Event{NewEvent("sessions", "", nil)}, eventFilter(r))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?types=" + EventSessionKilled)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, expected text/event-stream", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		// Skip data line and blank separator
+		reader.ReadString('\n')
+		reader.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+
+	if got := readEvent(); got != "event: sessions" {
+		t.Errorf("First event = %q, expected the snapshot", got)
+	}
+
+	// Filtered out by ?types=
+	hub.Publish(NewEvent(EventSessionCreated, "a", nil))
+	hub.Publish(NewEvent(EventSessionKilled, "a", nil))
+
+	if got := readEvent(); got != "event: "+EventSessionKilled {
+		t.Errorf("Second event = %q, expected %s", got, EventSessionKilled)
+	}
+}
@@ -3,24 +3,39 @@ package api
 
 import (
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/chrote/server/internal/core"
 )
 
+// HealthCheck reports whether a server component is healthy, with details about its state
+type HealthCheck func() (healthy bool, detail interface{})
+
 // HealthHandler handles health check endpoints
 type HealthHandler struct {
 	version string
+
+	mu         sync.Mutex
+	components map[string]HealthCheck
 }
 
 // NewHealthHandler creates a new HealthHandler
 func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{version: "0.2.0"}
+	return NewHealthHandlerWithVersion("0.2.0")
 }
 
 // NewHealthHandlerWithVersion creates a new HealthHandler with a custom version
 func NewHealthHandlerWithVersion(version string) *HealthHandler {
-	return &HealthHandler{version: version}
+	return &HealthHandler{version: version, components: make(map[string]HealthCheck)}
+}
+
+// AddComponent includes a component's state in health responses
+// An unhealthy component turns the overall status to "degraded"
+func (h *HealthHandler) AddComponent(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.components[name] = check
 }
 
 // RegisterRoutes registers the health routes on the given mux
@@ -31,11 +46,33 @@ func (h *HealthHandler) RegisterRoutes(mux *http.ServeMux) {
 
 // Health handles GET /api/health
 func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
-	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"status":    "ok",
+	h.mu.Lock()
+	checks := make(map[string]HealthCheck, len(h.components))
+	for name, check := range h.components {
+		checks[name] = check
+	}
+	h.mu.Unlock()
+
+	// Degraded is still 200: the server itself is up and heals its components
+	status := "ok"
+	components := make(map[string]interface{}, len(checks))
+	for name, check := range checks {
+		healthy, detail := check()
+		if !healthy {
+			status = "degraded"
+		}
+		components[name] = detail
+	}
+
+	response := map[string]interface{}{
+		"status":    status,
 		"version":   h.version,
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
-	})
+	}
+	if len(components) > 0 {
+		response["components"] = components
+	}
+	core.WriteJSON(w, http.StatusOK, response)
 }
 
 // Version handles GET /api/version
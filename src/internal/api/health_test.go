@@ -49,3 +49,31 @@ func TestHealthHandler_RegisterRoutes(t *testing.T) {
 		t.Errorf("Route not registered correctly, got status %d", recorder.Code)
 	}
 }
+
+func TestHealthHandler_Components(t *testing.T) {
+	handler := NewHealthHandler()
+	handler.AddComponent("ttyd", func() (bool, interface{}) {
+		return false, map[string]interface{}{"state": "backoff", "crashes": 2}
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.Health(recorder, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code = %d, expected %d", recorder.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Status     string                            `json:"status"`
+		Components map[string]map[string]interface{} `json:"components"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Status != "degraded" {
+		t.Errorf("status = %q, expected 'degraded' with an unhealthy component", response.Status)
+	}
+	if response.Components["ttyd"]["state"] != "backoff" {
+		t.Errorf("components = %v, expected ttyd state", response.Components)
+	}
+}
@@ -20,6 +20,14 @@ import (
 type TmuxHandler struct {
 	cache      *sessionsCache
 	colorRegex *regexp.Regexp
+	events     *EventHub
+	watcher    *sessionWatcher
+	monitor    *sessionMonitor
+	rotator    *logRotator
+	watchers   *watcherEngine
+
+	templatesMu sync.Mutex // serializes reads and writes of the templates file
+	watchersMu  sync.Mutex // serializes reads and writes of the watchers file
 }
 
 type sessionsCache struct {
@@ -27,6 +35,7 @@ type sessionsCache struct {
 	data      *SessionsResponse
 	timestamp time.Time
 	ttl       time.Duration
+	live      bool // kept current by the session watcher, ignore ttl
 }
 
 // SessionsResponse is the response for listing sessions
@@ -39,7 +48,9 @@ type SessionsResponse struct {
 
 // CreateSessionRequest is the request body for creating a session
 type CreateSessionRequest struct {
-	Name string `json:"name"`
+	Name     string            `json:"name"`
+	Template string            `json:"template,omitempty"` // Launch from a stored template (see /api/tmux/templates)
+	Vars     map[string]string `json:"vars,omitempty"`     // Values for the template's {{variables}}
 }
 
 // RenameSessionRequest is the request body for renaming a session
@@ -59,12 +70,36 @@ type AppearanceRequest struct {
 
 // NewTmuxHandler creates a new TmuxHandler
 func NewTmuxHandler() *TmuxHandler {
-	return &TmuxHandler{
+	h := &TmuxHandler{
 		cache: &sessionsCache{
 			ttl: time.Second,
 		},
 		colorRegex: regexp.MustCompile(`^#[0-9A-Fa-f]{3,6}$|^[a-zA-Z]+$|^default$`),
+		events:     NewEventHub(),
 	}
+	h.watcher = newSessionWatcher(h)
+	h.monitor = newSessionMonitor(h)
+	h.rotator = &logRotator{}
+	h.watchers = newWatcherEngine(h)
+	return h
+}
+
+// Start loads the grouping rules and starts the background session watcher,
+// state monitor, log rotator and output watchers
+func (h *TmuxHandler) Start() {
+	loadGrouping()
+	h.watcher.Start()
+	h.monitor.Start()
+	h.rotator.Start()
+	h.watchers.Start()
+}
+
+// Stop stops the background session watcher, state monitor, log rotator and output watchers
+func (h *TmuxHandler) Stop() {
+	h.watchers.Stop()
+	h.rotator.Stop()
+	h.monitor.Stop()
+	h.watcher.Stop()
 }
 
 // RegisterRoutes registers the tmux routes on the given mux
@@ -72,18 +107,66 @@ func (h *TmuxHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/tmux/sessions", h.ListSessions)
 	mux.HandleFunc("POST /api/tmux/sessions", h.CreateSession)
 	mux.HandleFunc("DELETE /api/tmux/sessions/all", h.DeleteAllSessions)
+	mux.HandleFunc("POST /api/tmux/sessions/bulk", h.BulkSessions)
 	mux.HandleFunc("DELETE /api/tmux/sessions/{name}", h.DeleteSession)
 	mux.HandleFunc("PATCH /api/tmux/sessions/{name}", h.RenameSession)
+	mux.HandleFunc("POST /api/tmux/sessions/{name}/protect", h.ProtectSession)
+	mux.HandleFunc("DELETE /api/tmux/sessions/{name}/protect", h.UnprotectSession)
+	mux.HandleFunc("POST /api/tmux/sessions/{name}/logging", h.EnableLogging)
+	mux.HandleFunc("DELETE /api/tmux/sessions/{name}/logging", h.DisableLogging)
+	mux.HandleFunc("POST /api/tmux/sessions/{name}/recording", h.StartRecording)
+	mux.HandleFunc("DELETE /api/tmux/sessions/{name}/recording", h.StopRecording)
+	mux.HandleFunc("GET /api/tmux/sessions/{name}/capture", h.CapturePane)
+	mux.HandleFunc("POST /api/tmux/sessions/{name}/input", h.SendInput)
+	mux.HandleFunc("GET /api/tmux/sessions/{name}/windows", h.ListWindows)
+	mux.HandleFunc("POST /api/tmux/sessions/{name}/windows", h.CreateWindow)
+	mux.HandleFunc("PATCH /api/tmux/sessions/{name}/windows/{window}", h.RenameWindow)
+	mux.HandleFunc("DELETE /api/tmux/sessions/{name}/windows/{window}", h.DeleteWindow)
+	mux.HandleFunc("POST /api/tmux/sessions/{name}/windows/{window}/select", h.SelectWindow)
+	mux.HandleFunc("POST /api/tmux/sessions/{name}/windows/{window}/split", h.SplitPane)
+	mux.HandleFunc("POST /api/tmux/sessions/{name}/windows/{window}/panes/{pane}/select", h.SelectPane)
+	mux.HandleFunc("DELETE /api/tmux/sessions/{name}/windows/{window}/panes/{pane}", h.DeletePane)
+	mux.HandleFunc("POST /api/tmux/snapshot", h.TakeSnapshot)
+	mux.HandleFunc("POST /api/tmux/restore", h.RestoreSnapshot)
+	mux.HandleFunc("GET /api/tmux/snapshots", h.ListSnapshots)
+	mux.HandleFunc("DELETE /api/tmux/snapshots/{snapshot}", h.DeleteSnapshot)
+	mux.HandleFunc("GET /api/tmux/search", h.SearchSessions)
+	mux.HandleFunc("GET /api/tmux/logs", h.ListLogs)
+	mux.HandleFunc("GET /api/tmux/logs/{session}", h.ListSessionLogs)
+	mux.HandleFunc("GET /api/tmux/logs/{session}/search", h.SearchSessionLogs)
+	mux.HandleFunc("GET /api/tmux/logs/{session}/files/{file}", h.DownloadSessionLog)
+	mux.HandleFunc("GET /api/tmux/recordings", h.ListRecordings)
+	mux.HandleFunc("GET /api/tmux/recordings/{session}/{file}", h.GetRecording)
+	mux.HandleFunc("DELETE /api/tmux/recordings/{session}/{file}", h.DeleteRecording)
+	mux.HandleFunc("GET /api/tmux/watchers", h.ListWatchers)
+	mux.HandleFunc("GET /api/tmux/watchers/{watcher}", h.GetWatcher)
+	mux.HandleFunc("PUT /api/tmux/watchers/{watcher}", h.PutWatcher)
+	mux.HandleFunc("DELETE /api/tmux/watchers/{watcher}", h.DeleteWatcher)
+	mux.HandleFunc("GET /api/tmux/grouping", h.GetGrouping)
+	mux.HandleFunc("PUT /api/tmux/grouping", h.PutGrouping)
+	mux.HandleFunc("GET /api/tmux/templates", h.ListTemplates)
+	mux.HandleFunc("GET /api/tmux/templates/{template}", h.GetTemplate)
+	mux.HandleFunc("PUT /api/tmux/templates/{template}", h.PutTemplate)
+	mux.HandleFunc("DELETE /api/tmux/templates/{template}", h.DeleteTemplate)
 	mux.HandleFunc("POST /api/tmux/appearance", h.ApplyAppearance)
+	mux.HandleFunc("GET /api/events", h.StreamEvents)
 }
 
 // runTmux executes a tmux command with proper environment
 func (h *TmuxHandler) runTmux(args ...string) (string, error) {
+	return h.runTmuxWithInput("", args...)
+}
+
+// runTmuxWithInput executes a tmux command, feeding stdin to it (e.g. for load-buffer -)
+func (h *TmuxHandler) runTmuxWithInput(stdin string, args ...string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "tmux", args...)
 	cmd.Env = core.GetTmuxEnv()
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -95,20 +178,64 @@ func (h *TmuxHandler) runTmux(args ...string) (string, error) {
 	return string(output), nil
 }
 
+// tmuxErrorStatus maps a tmux error to an HTTP status code
+func tmuxErrorStatus(err error) int {
+	errStr := err.Error()
+	for _, msg := range []string{"can't find session", "can't find window", "can't find pane"} {
+		if strings.Contains(errStr, msg) {
+			return http.StatusNotFound
+		}
+	}
+	return http.StatusInternalServerError
+}
+
 // ListSessions handles GET /api/tmux/sessions
+// While the session watcher is running the cache is kept current by tmux
+// notifications, so requests never have to shell out to tmux themselves
+// Query: sort (group, activity, created or name; default group)
 func (h *TmuxHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	response := h.sessions()
+
+	if order := r.URL.Query().Get("sort"); order != "" && order != core.SortByGroup {
+		if !core.IsValidSortOrder(order) {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid sort. Use group, activity, created or name.")
+			return
+		}
+		response = response.sortedBy(order)
+	}
+
+	core.WriteJSON(w, http.StatusOK, response)
+}
+
+// sortedBy returns a copy of the response with sessions re-sorted and re-grouped
+// The cached response is shared between requests and must not be modified
+func (s *SessionsResponse) sortedBy(order string) *SessionsResponse {
+	sorted := *s
+	sorted.Sessions = append([]core.Session{}, s.Sessions...)
+	core.SortSessionsBy(sorted.Sessions, order)
+	sorted.Grouped = core.GroupSessions(sorted.Sessions)
+	return &sorted
+}
+
+// sessions returns the cached session list, re-listing from tmux when it is stale
+func (h *TmuxHandler) sessions() *SessionsResponse {
 	// Check cache
 	h.cache.mu.RLock()
-	if h.cache.data != nil && time.Since(h.cache.timestamp) < h.cache.ttl {
+	if h.cache.data != nil && (h.cache.live || time.Since(h.cache.timestamp) < h.cache.ttl) {
 		data := h.cache.data
 		h.cache.mu.RUnlock()
-		core.WriteJSON(w, http.StatusOK, data)
-		return
+		return data
 	}
 	h.cache.mu.RUnlock()
 
-	// Fetch sessions
-	output, err := h.runTmux("list-sessions", "-F", "#{session_name}:#{session_windows}:#{session_attached}")
+	response := h.fetchSessions()
+	h.storeSessions(response)
+	return response
+}
+
+// fetchSessions lists sessions from tmux and builds a sorted, grouped response
+func (h *TmuxHandler) fetchSessions() *SessionsResponse {
+	output, err := h.runTmux("list-sessions", "-F", core.SessionFormat)
 
 	response := &SessionsResponse{
 		Sessions:  []core.Session{},
@@ -130,44 +257,54 @@ func (h *TmuxHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
 		if !isNoServer {
 			response.Error = errStr
 		}
-	} else {
-		lines := strings.Split(strings.TrimSpace(output), "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			parts := strings.Split(line, ":")
-			if len(parts) >= 3 {
-				windows, _ := strconv.Atoi(parts[1])
-				if windows == 0 {
-					windows = 1
-				}
-				session := core.Session{
-					Name:     parts[0],
-					Windows:  windows,
-					Attached: parts[2] == "1",
-					Group:    core.CategorizeSession(parts[0]),
-				}
-				response.Sessions = append(response.Sessions, session)
-			}
+		return response
+	}
+
+	response.Sessions = core.ParseSessions(output, h.countClients())
+	h.applyOptionGrouping(response.Sessions)
+	h.monitor.annotate(response.Sessions)
+	for i := range response.Sessions {
+		if protectedSessions[response.Sessions[i].Name] {
+			response.Sessions[i].Protected = true
 		}
+	}
+	core.SortSessions(response.Sessions)
+	response.Grouped = core.GroupSessions(response.Sessions)
+	return response
+}
 
-		core.SortSessions(response.Sessions)
-		response.Grouped = core.GroupSessions(response.Sessions)
+// countClients returns the number of attached clients per session ID
+// Control mode clients (including the session watcher's own) are not counted
+func (h *TmuxHandler) countClients() map[string]int {
+	counts := make(map[string]int)
+	output, err := h.runTmux("list-clients", "-F", "#{client_control_mode}|#{session_id}")
+	if err != nil {
+		return counts
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 2)
+		if len(parts) == 2 && parts[0] != "1" {
+			counts[parts[1]]++
+		}
 	}
+	return counts
+}
 
-	// Update cache
+// storeSessions replaces the cached session list
+func (h *TmuxHandler) storeSessions(response *SessionsResponse) {
 	h.cache.mu.Lock()
 	h.cache.data = response
 	h.cache.timestamp = time.Now()
 	h.cache.mu.Unlock()
-
-	core.WriteJSON(w, http.StatusOK, response)
 }
 
-// invalidateCache clears the sessions cache
+// invalidateCache refreshes the session list after a mutation
+// With the watcher running this re-lists immediately so events go out before
+// the API responds; otherwise the cache is simply cleared
 func (h *TmuxHandler) invalidateCache() {
+	if h.watcher.refreshNow() {
+		return
+	}
 	h.cache.mu.Lock()
 	h.cache.data = nil
 	h.cache.timestamp = time.Time{}
@@ -196,23 +333,41 @@ func (h *TmuxHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Create the session (detached)
-	_, err := h.runTmux("new-session", "-d", "-s", name, "-c", core.GetWorkDir())
-	if err != nil {
-		core.WriteError(w, http.StatusBadRequest, "TMUX_ERROR", err.Error())
-		return
+	if req.Template != "" {
+		template, code, msg := h.resolveTemplate(req.Template, name, req.Vars)
+		if code != "" {
+			core.WriteError(w, core.GetErrorStatusCode(code), code, msg)
+			return
+		}
+		if err := h.launchTemplate(name, template); err != nil {
+			h.invalidateCache()
+			core.WriteError(w, http.StatusBadRequest, "TMUX_ERROR", err.Error())
+			return
+		}
+	} else {
+		// Create the session (detached)
+		_, err := h.runTmux("new-session", "-d", "-s", name, "-c", core.GetWorkDir())
+		if err != nil {
+			core.WriteError(w, http.StatusBadRequest, "TMUX_ERROR", err.Error())
+			return
+		}
 	}
 
 	h.invalidateCache()
 
-	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+	response := map[string]interface{}{
 		"success":   true,
 		"session":   name,
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
-	})
+	}
+	if req.Template != "" {
+		response["template"] = req.Template
+	}
+	core.WriteJSON(w, http.StatusOK, response)
 }
 
 // DeleteSession handles DELETE /api/tmux/sessions/{name}
+// Protected sessions are refused unless force=1 is given, which is audited
 func (h *TmuxHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
 	sessionName := r.PathValue("name")
 
@@ -222,6 +377,14 @@ func (h *TmuxHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.isProtected(sessionName) {
+		if !isForced(r) {
+			core.WriteError(w, http.StatusForbidden, "FORBIDDEN", "Session "+sessionName+" is protected. Unprotect it or pass force=1.")
+			return
+		}
+		auditForced(r, "kill", sessionName)
+	}
+
 	_, err := h.runTmux("kill-session", "-t", sessionName)
 	if err != nil {
 		core.WriteError(w, http.StatusInternalServerError, "TMUX_ERROR", err.Error())
@@ -237,12 +400,13 @@ func (h *TmuxHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// protectedSessions is the list of sessions that should not be killed by nuke
+// protectedSessions are always protected, whatever their @chrote-protected option says
 var protectedSessions = map[string]bool{
 	"chrote-chat": true,
 }
 
 // DeleteAllSessions handles DELETE /api/tmux/sessions/all
+// Protected sessions are preserved unless force=1 is given, which is audited per session
 func (h *TmuxHandler) DeleteAllSessions(w http.ResponseWriter, r *http.Request) {
 	// Verify the request came from the dashboard UI
 	confirmHeader := r.Header.Get("X-Nuke-Confirm")
@@ -251,21 +415,27 @@ func (h *TmuxHandler) DeleteAllSessions(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	force := isForced(r)
+
 	// Get list of all sessions first
-	output, err := h.runTmux("list-sessions", "-F", "#{session_name}")
+	output, err := h.runTmux("list-sessions", "-F", "#{"+core.ProtectedOption+"}|#{session_name}")
 	var sessionNames []string
 	var protectedNames []string
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(output), "\n")
 		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				if protectedSessions[line] {
-					protectedNames = append(protectedNames, line)
-				} else {
-					sessionNames = append(sessionNames, line)
+			protected, name, _ := strings.Cut(strings.TrimSpace(line), "|")
+			if name == "" {
+				continue
+			}
+			if protectedSessions[name] || protected == "1" {
+				if !force {
+					protectedNames = append(protectedNames, name)
+					continue
 				}
+				auditForced(r, "nuke", name)
 			}
+			sessionNames = append(sessionNames, name)
 		}
 	}
 
@@ -0,0 +1,203 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// Bulk session actions
+const (
+	BulkKill          = "kill"
+	BulkSendKeys      = "send-keys"
+	BulkRenamePrefix  = "rename-prefix"
+	BulkDetachClients = "detach-clients"
+)
+
+// Per-session bulk result statuses
+const (
+	BulkStatusOK      = "ok"
+	BulkStatusSkipped = "skipped"
+	BulkStatusError   = "error"
+	BulkStatusPlanned = "planned" // dry run
+)
+
+// BulkRequest is the request body for bulk session operations
+type BulkRequest struct {
+	Selector core.SessionSelector `json:"selector"`
+	Action   string               `json:"action"`
+	DryRun   bool                 `json:"dryRun,omitempty"`
+	Force    bool                 `json:"force,omitempty"` // Also act on protected sessions (audited)
+
+	// send-keys
+	Text  string   `json:"text,omitempty"`
+	Keys  []string `json:"keys,omitempty"`
+	Enter bool     `json:"enter,omitempty"`
+
+	// rename-prefix: names starting with From get it replaced by To (From empty prepends To)
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// BulkResult is the outcome of a bulk action for one session
+type BulkResult struct {
+	Session string `json:"session"`
+	Status  string `json:"status"`
+	NewName string `json:"newName,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// validateBulkRequest checks the action and its parameters
+func validateBulkRequest(req BulkRequest) (bool, string) {
+	if valid, errMsg := core.ValidateSelector(req.Selector); !valid {
+		return false, errMsg
+	}
+
+	switch req.Action {
+	case BulkKill, BulkDetachClients:
+	case BulkSendKeys:
+		if req.Text == "" && len(req.Keys) == 0 && !req.Enter {
+			return false, "send-keys needs text, keys or enter."
+		}
+		if req.Text != "" {
+			if valid, errMsg := core.ValidateInputText(req.Text, "text"); !valid {
+				return false, errMsg
+			}
+		}
+		for _, key := range req.Keys {
+			if valid, errMsg := core.ValidateKeyName(key); !valid {
+				return false, errMsg
+			}
+		}
+	case BulkRenamePrefix:
+		if req.From == "" && req.To == "" {
+			return false, "rename-prefix needs from or to."
+		}
+	case "":
+		return false, "action is required."
+	default:
+		return false, "Invalid action. Use kill, send-keys, rename-prefix or detach-clients."
+	}
+	return true, ""
+}
+
+// BulkSessions handles POST /api/tmux/sessions/bulk
+// Applies one action to every session matching the selector and reports per-session results
+// With dryRun the matching sessions are reported without touching them
+func (h *TmuxHandler) BulkSessions(w http.ResponseWriter, r *http.Request) {
+	var req BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+	if valid, errMsg := validateBulkRequest(req); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	response := h.fetchSessions()
+	if response.Error != "" {
+		core.WriteError(w, http.StatusInternalServerError, "TMUX_ERROR", response.Error)
+		return
+	}
+	selected := core.SelectSessions(response.Sessions, req.Selector, time.Now())
+
+	results := make([]BulkResult, 0, len(selected))
+	failed := 0
+	for _, session := range selected {
+		result := h.bulkAction(r, session, req)
+		if result.Status == BulkStatusError {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if !req.DryRun && len(selected) > 0 {
+		h.invalidateCache()
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   failed == 0,
+		"action":    req.Action,
+		"dryRun":    req.DryRun,
+		"matched":   len(selected),
+		"failed":    failed,
+		"results":   results,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// bulkAction applies the request's action to one session, or plans it for a dry run
+func (h *TmuxHandler) bulkAction(r *http.Request, session core.Session, req BulkRequest) BulkResult {
+	name := session.Name
+	result := BulkResult{Session: name, Status: BulkStatusOK}
+
+	guarded := req.Action == BulkKill || req.Action == BulkRenamePrefix
+	if guarded && session.Protected && !req.Force {
+		result.Status = BulkStatusSkipped
+		result.Reason = "protected session"
+		return result
+	}
+
+	if req.Action == BulkRenamePrefix {
+		if req.From != "" && !strings.HasPrefix(name, req.From) {
+			result.Status = BulkStatusSkipped
+			result.Reason = "name does not start with " + req.From
+			return result
+		}
+		result.NewName = req.To + strings.TrimPrefix(name, req.From)
+		if valid, errMsg := core.ValidateSessionName(result.NewName, "new session name"); !valid {
+			result.Status = BulkStatusError
+			result.Reason = errMsg
+			return result
+		}
+	}
+
+	if req.DryRun {
+		result.Status = BulkStatusPlanned
+		return result
+	}
+	if guarded && session.Protected {
+		auditForced(r, "bulk-"+req.Action, name)
+	}
+
+	var err error
+	switch req.Action {
+	case BulkKill:
+		_, err = h.runTmux("kill-session", "-t", "="+name)
+	case BulkSendKeys:
+		err = h.sendInput("="+name+":", SendInputRequest{Text: req.Text, Keys: req.Keys, Enter: req.Enter})
+	case BulkRenamePrefix:
+		_, err = h.runTmux("rename-session", "-t", "="+name, result.NewName)
+	case BulkDetachClients:
+		err = h.detachClients(name)
+	}
+	if err != nil {
+		result.Status = BulkStatusError
+		result.Reason = err.Error()
+	}
+	return result
+}
+
+// detachClients detaches every client attached to a session
+// Control mode clients, such as the session watcher's, are left attached
+func (h *TmuxHandler) detachClients(name string) error {
+	output, err := h.runTmux("list-clients", "-t", "="+name, "-F", "#{client_control_mode}|#{client_name}")
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 2)
+		if len(parts) != 2 || parts[0] == "1" {
+			continue
+		}
+		if _, err := h.runTmux("detach-client", "-t", parts[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
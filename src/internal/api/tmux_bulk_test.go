@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chrote/server/internal/core"
+)
+
+func TestTmuxHandler_BulkSessions_Validation(t *testing.T) {
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid JSON", `{bad`},
+		{"empty selector", `{"action":"kill","selector":{}}`},
+		{"missing action", `{"selector":{"group":"gt-rig"}}`},
+		{"unknown action", `{"action":"explode","selector":{"group":"gt-rig"}}`},
+		{"bad regex", `{"action":"kill","selector":{"regex":"("}}`},
+		{"send-keys without input", `{"action":"send-keys","selector":{"group":"gt-rig"}}`},
+		{"send-keys bad key", `{"action":"send-keys","keys":["Hyper-X"],"selector":{"group":"gt-rig"}}`},
+		{"rename-prefix without prefixes", `{"action":"rename-prefix","selector":{"group":"gt-rig"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/bulk", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d: %s", rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestTmuxHandler_BulkAction_DryRun(t *testing.T) {
+	handler := NewTmuxHandler()
+
+	tests := []struct {
+		name    string
+		session string
+		req     BulkRequest
+		status  string
+		newName string
+	}{
+		{"kill planned", "gt-rig-1", BulkRequest{Action: BulkKill, DryRun: true}, BulkStatusPlanned, ""},
+		{"kill protected", "hq-mayor", BulkRequest{Action: BulkKill, DryRun: true}, BulkStatusSkipped, ""},
+		{"kill protected forced", "hq-mayor", BulkRequest{Action: BulkKill, Force: true, DryRun: true}, BulkStatusPlanned, ""},
+		{"send-keys to protected", "hq-mayor", BulkRequest{Action: BulkSendKeys, Enter: true, DryRun: true}, BulkStatusPlanned, ""},
+		{"rename planned", "gt-rig-1", BulkRequest{Action: BulkRenamePrefix, From: "gt-rig-", To: "old-", DryRun: true}, BulkStatusPlanned, "old-1"},
+		{"rename prefix mismatch", "main", BulkRequest{Action: BulkRenamePrefix, From: "gt-rig-", To: "old-", DryRun: true}, BulkStatusSkipped, ""},
+		{"rename invalid result", "gt-rig-1", BulkRequest{Action: BulkRenamePrefix, To: "bad name ", DryRun: true}, BulkStatusError, "bad name gt-rig-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := core.Session{Name: tt.session, Protected: tt.session == "hq-mayor"}
+			req := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/bulk", nil)
+			result := handler.bulkAction(req, session, tt.req)
+			if result.Status != tt.status || result.NewName != tt.newName {
+				t.Errorf("bulkAction() = %+v, expected status %q newName %q", result, tt.status, tt.newName)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// maxCaptureScrollback caps how much history a single capture may request
+const maxCaptureScrollback = 50000
+
+// CaptureLine is one line of pane output in the JSON lines capture format
+type CaptureLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// capturePane runs capture-pane for a target and returns the raw output
+// scrollback is the number of history lines above the visible screen to include
+func (h *TmuxHandler) capturePane(target string, scrollback int, ansi, join bool) (string, error) {
+	args := []string{"capture-pane", "-p", "-t", target}
+	if scrollback > 0 {
+		args = append(args, "-S", "-"+strconv.Itoa(scrollback))
+	}
+	if ansi {
+		args = append(args, "-e")
+	}
+	if join {
+		args = append(args, "-J")
+	}
+	return h.runTmux(args...)
+}
+
+// CapturePane handles GET /api/tmux/sessions/{name}/capture
+// Query: window, pane, scrollback (lines), format (text|ansi|json), join (1 to join wrapped lines)
+func (h *TmuxHandler) CapturePane(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	target, errMsg := core.BuildTarget(r.PathValue("name"), query.Get("window"), query.Get("pane"))
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	scrollback := 0
+	if s := query.Get("scrollback"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > maxCaptureScrollback {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST",
+				"Invalid scrollback. Use a number between 0 and "+strconv.Itoa(maxCaptureScrollback)+".")
+			return
+		}
+		scrollback = n
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = "text"
+	}
+	if format != "text" && format != "ansi" && format != "json" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid format. Use text, ansi or json.")
+		return
+	}
+
+	output, err := h.capturePane(target, scrollback, format == "ansi", query.Get("join") == "1")
+	if err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	switch format {
+	case "json":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		for i, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+			enc.Encode(CaptureLine{Line: i + 1, Text: line})
+		}
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(output))
+	}
+}
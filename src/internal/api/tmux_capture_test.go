@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTmuxHandler_CapturePane_InvalidParams(t *testing.T) {
+	handler := NewTmuxHandler()
+
+	tests := []struct {
+		name    string
+		session string
+		query   string
+	}{
+		{"invalid session", "bad@name", ""},
+		{"invalid window", "main", "?window=abc"},
+		{"pane without window", "main", "?pane=1"},
+		{"negative scrollback", "main", "?scrollback=-5"},
+		{"huge scrollback", "main", "?scrollback=9999999"},
+		{"unknown format", "main", "?format=html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/tmux/sessions/"+tt.session+"/capture"+tt.query, nil)
+			req.SetPathValue("name", tt.session)
+			recorder := httptest.NewRecorder()
+
+			handler.CapturePane(recorder, req)
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("Status code = %d, expected %d", recorder.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestTmuxErrorStatus(t *testing.T) {
+	tests := []struct {
+		err      string
+		expected int
+	}{
+		{"exit status 1: can't find session: foo", http.StatusNotFound},
+		{"exit status 1: can't find window: 5", http.StatusNotFound},
+		{"exit status 1: no server running on /tmp/tmux-0/default", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := tmuxErrorStatus(errors.New(tt.err)); got != tt.expected {
+			t.Errorf("tmuxErrorStatus(%q) = %d, expected %d", tt.err, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"bufio"
+	"log"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+const (
+	// watcherPollInterval is how often sessions are re-listed while no control client is connected
+	watcherPollInterval = 2 * time.Second
+	// watcherFallbackInterval re-lists even with a control client, catching changes tmux doesn't notify (e.g. attach)
+	watcherFallbackInterval = 5 * time.Second
+	// watcherDebounce coalesces bursts of notifications into one re-list
+	watcherDebounce = 100 * time.Millisecond
+	// controlRetryMin and controlRetryMax bound the backoff between control client reconnects
+	controlRetryMin = 2 * time.Second
+	controlRetryMax = 30 * time.Second
+)
+
+// controlNotifications are the control mode notifications that can change the session list
+var controlNotifications = map[string]bool{
+	"%sessions-changed":       true,
+	"%session-changed":        true,
+	"%session-renamed":        true,
+	"%window-add":             true,
+	"%window-close":           true,
+	"%unlinked-window-add":    true,
+	"%unlinked-window-close":  true,
+	"%client-session-changed": true,
+	"%client-detached":        true,
+	"%exit":                   true,
+}
+
+// sessionWatcher keeps the sessions cache current and publishes session events
+// A tmux control mode client (tmux -C) tells it when something changed, so the
+// server lists sessions once per change instead of once per polling client
+type sessionWatcher struct {
+	handler *TmuxHandler
+	refresh chan struct{}
+	wg      sync.WaitGroup
+
+	mu        sync.Mutex
+	running   bool
+	connected bool
+	stop      chan struct{}
+
+	refreshMu   sync.Mutex // serializes list-and-diff runs
+	sessions    []core.Session
+	lastRefresh time.Time
+}
+
+// newSessionWatcher creates a watcher for the given handler
+func newSessionWatcher(h *TmuxHandler) *sessionWatcher {
+	return &sessionWatcher{
+		handler: h,
+		refresh: make(chan struct{}, 1),
+	}
+}
+
+// Start starts the watcher and its control mode client
+func (sw *sessionWatcher) Start() {
+	sw.mu.Lock()
+	if sw.running {
+		sw.mu.Unlock()
+		return
+	}
+	sw.running = true
+	sw.stop = make(chan struct{})
+	stop := sw.stop
+	sw.mu.Unlock()
+
+	sw.refreshNow()
+
+	sw.wg.Add(2)
+	go sw.run(stop)
+	go sw.controlLoop(stop)
+	log.Printf("Session watcher started")
+}
+
+// Stop stops the watcher and falls back to TTL caching
+func (sw *sessionWatcher) Stop() {
+	sw.mu.Lock()
+	if !sw.running {
+		sw.mu.Unlock()
+		return
+	}
+	sw.running = false
+	close(sw.stop)
+	sw.mu.Unlock()
+
+	sw.wg.Wait()
+
+	sw.handler.cache.mu.Lock()
+	sw.handler.cache.live = false
+	sw.handler.cache.mu.Unlock()
+}
+
+// isRunning returns whether the watcher has been started
+func (sw *sessionWatcher) isRunning() bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.running
+}
+
+// isConnected returns whether the control mode client is attached
+func (sw *sessionWatcher) isConnected() bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.connected
+}
+
+// trigger schedules a re-list without blocking
+func (sw *sessionWatcher) trigger() {
+	select {
+	case sw.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// refreshNow re-lists sessions, updates the cache and publishes the differences
+// Returns false if the watcher isn't running
+func (sw *sessionWatcher) refreshNow() bool {
+	if !sw.isRunning() {
+		return false
+	}
+
+	sw.refreshMu.Lock()
+	defer sw.refreshMu.Unlock()
+
+	response := sw.handler.fetchSessions()
+	sw.lastRefresh = time.Now()
+
+	sw.handler.cache.mu.Lock()
+	sw.handler.cache.data = response
+	sw.handler.cache.timestamp = time.Now()
+	sw.handler.cache.live = true
+	sw.handler.cache.mu.Unlock()
+
+	// A failed listing says nothing about which sessions exist, don't diff it
+	if response.Error != "" {
+		return true
+	}
+
+	for _, event := range diffSessions(sw.sessions, response.Sessions) {
+		sw.handler.events.Publish(event)
+	}
+	sw.sessions = response.Sessions
+	return true
+}
+
+// run re-lists on notifications and on a fallback timer
+func (sw *sessionWatcher) run(stop chan struct{}) {
+	defer sw.wg.Done()
+
+	ticker := time.NewTicker(watcherPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-sw.refresh:
+			time.Sleep(watcherDebounce)
+			select {
+			case <-sw.refresh:
+			default:
+			}
+			sw.refreshNow()
+		case <-ticker.C:
+			interval := watcherPollInterval
+			if sw.isConnected() {
+				interval = watcherFallbackInterval
+			}
+			sw.refreshMu.Lock()
+			due := time.Since(sw.lastRefresh) >= interval
+			sw.refreshMu.Unlock()
+			if due {
+				sw.refreshNow()
+			}
+		}
+	}
+}
+
+// controlLoop keeps a control mode client attached, reconnecting with backoff
+func (sw *sessionWatcher) controlLoop(stop chan struct{}) {
+	defer sw.wg.Done()
+
+	backoff := controlRetryMin
+	for {
+		if target := sw.controlTarget(); target != "" {
+			started := time.Now()
+			sw.runControlClient(target, stop)
+			if time.Since(started) > time.Minute {
+				backoff = controlRetryMin
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, controlRetryMax)
+	}
+}
+
+// controlTarget picks the session ID to attach the control client to
+// Prefers the long-lived chrote-chat session so ordinary sessions aren't touched
+func (sw *sessionWatcher) controlTarget() string {
+	sw.refreshMu.Lock()
+	defer sw.refreshMu.Unlock()
+
+	for _, s := range sw.sessions {
+		if s.Name == ChroteChatSession {
+			return s.ID
+		}
+	}
+	if len(sw.sessions) > 0 {
+		return sw.sessions[0].ID
+	}
+	return ""
+}
+
+// runControlClient attaches a read-only control mode client and triggers a
+// re-list for every relevant notification until the client exits or stop closes
+func (sw *sessionWatcher) runControlClient(target string, stop chan struct{}) {
+	cmd := exec.Command("tmux", "-C", "attach-session", "-t", target, "-f", "no-output,ignore-size,read-only")
+	cmd.Env = core.GetTmuxEnv()
+
+	// Control mode exits when stdin closes, so hold it open for the client's lifetime
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return
+	}
+	defer stdin.Close()
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+
+	if err := cmd.Start(); err != nil {
+		log.Printf("Session watcher: failed to start tmux control client: %v", err)
+		return
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		select {
+		case <-stop:
+			cmd.Process.Kill()
+		case <-exited:
+		}
+	}()
+
+	sw.mu.Lock()
+	sw.connected = true
+	sw.mu.Unlock()
+	sw.trigger()
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "%") {
+			continue
+		}
+		name, _, _ := strings.Cut(line, " ")
+		if controlNotifications[name] {
+			sw.trigger()
+		}
+	}
+
+	cmd.Wait()
+	close(exited)
+
+	sw.mu.Lock()
+	sw.connected = false
+	sw.mu.Unlock()
+	// The control client usually exits because its session or the server went away
+	sw.trigger()
+}
+
+// diffSessions compares two session lists by tmux session ID and returns the events between them
+func diffSessions(previous, current []core.Session) []Event {
+	var events []Event
+
+	before := make(map[string]core.Session, len(previous))
+	for _, s := range previous {
+		before[s.ID] = s
+	}
+
+	seen := make(map[string]bool, len(current))
+	for _, s := range current {
+		seen[s.ID] = true
+		old, existed := before[s.ID]
+		if !existed {
+			events = append(events, NewEvent(EventSessionCreated, s.Name, map[string]interface{}{
+				"id":      s.ID,
+				"group":   s.Group,
+				"windows": s.Windows,
+			}))
+			continue
+		}
+
+		if old.Name != s.Name {
+			events = append(events, NewEvent(EventSessionRenamed, s.Name, map[string]interface{}{
+				"id":      s.ID,
+				"oldName": old.Name,
+				"newName": s.Name,
+			}))
+		}
+		if s.Clients > old.Clients {
+			events = append(events, NewEvent(EventSessionAttached, s.Name, map[string]interface{}{
+				"id":      s.ID,
+				"clients": s.Clients,
+			}))
+		}
+		if s.Clients < old.Clients {
+			events = append(events, NewEvent(EventSessionDetached, s.Name, map[string]interface{}{
+				"id":      s.ID,
+				"clients": s.Clients,
+			}))
+		}
+		if old.Windows != s.Windows {
+			events = append(events, NewEvent(EventSessionWindowsChanged, s.Name, map[string]interface{}{
+				"id":   s.ID,
+				"from": old.Windows,
+				"to":   s.Windows,
+			}))
+		}
+	}
+
+	for _, s := range previous {
+		if !seen[s.ID] {
+			events = append(events, NewEvent(EventSessionKilled, s.Name, map[string]interface{}{"id": s.ID}))
+		}
+	}
+
+	return events
+}
+
+// StreamEvents handles GET /api/events
+// Sends a "sessions" snapshot first, then session events as they happen (Server-Sent Events)
+// Query: types (comma-separated event types), session (only events for that session)
+func (h *TmuxHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	// Subscribe before taking the snapshot so no change falls in between
+	events, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	snapshot := NewEvent("sessions", "", map[string]interface{}{
+		"sessions": h.sessions().Sessions,
+	})
+	serveEventStream(w, r, events, []Event{snapshot}, eventFilter(r))
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/chrote/server/internal/core"
+)
+
+func TestDiffSessions(t *testing.T) {
+	previous := []core.Session{
+		{ID: "$1", Name: "hq-mayor", Windows: 1},
+		{ID: "$2", Name: "old-name", Windows: 1},
+		{ID: "$3", Name: "gt-rig-jack", Windows: 1, Attached: true, Clients: 1},
+		{ID: "$4", Name: "doomed", Windows: 1},
+	}
+	current := []core.Session{
+		{ID: "$1", Name: "hq-mayor", Windows: 3, Attached: true, Clients: 1},
+		{ID: "$2", Name: "new-name", Windows: 1},
+		{ID: "$3", Name: "gt-rig-jack", Windows: 1},
+		{ID: "$5", Name: "fresh", Windows: 1},
+	}
+
+	events := diffSessions(previous, current)
+
+	expected := []struct {
+		eventType string
+		session   string
+	}{
+		{EventSessionAttached, "hq-mayor"},
+		{EventSessionWindowsChanged, "hq-mayor"},
+		{EventSessionRenamed, "new-name"},
+		{EventSessionDetached, "gt-rig-jack"},
+		{EventSessionCreated, "fresh"},
+		{EventSessionKilled, "doomed"},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+	for i, e := range expected {
+		if events[i].Type != e.eventType || events[i].Session != e.session {
+			t.Errorf("Event %d = %s/%s, expected %s/%s", i, events[i].Type, events[i].Session, e.eventType, e.session)
+		}
+	}
+
+	if events[2].Data["oldName"] != "old-name" {
+		t.Errorf("Rename event oldName = %v, expected old-name", events[2].Data["oldName"])
+	}
+}
+
+func TestDiffSessions_NoChanges(t *testing.T) {
+	sessions := []core.Session{{ID: "$1", Name: "main", Windows: 2}}
+	if events := diffSessions(sessions, sessions); len(events) != 0 {
+		t.Errorf("Expected no events, got %+v", events)
+	}
+}
+
+func TestSessionWatcher_NotRunning(t *testing.T) {
+	handler := NewTmuxHandler()
+
+	if handler.watcher.refreshNow() {
+		t.Error("refreshNow() should report false before Start()")
+	}
+}
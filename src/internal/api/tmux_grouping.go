@@ -0,0 +1,109 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// loadGrouping activates the grouping rules stored in the config dir
+func loadGrouping() {
+	var cfg core.GroupingConfig
+	if err := core.LoadConfigFile(core.GroupingFile, &cfg); err != nil {
+		log.Printf("Grouping: failed to read %s: %v", core.GroupingFile, err)
+		return
+	}
+	if err := core.SetGroupingConfig(cfg); err != nil {
+		log.Printf("Grouping: ignoring invalid %s: %v", core.GroupingFile, err)
+	}
+}
+
+// GetGrouping handles GET /api/tmux/grouping
+func (h *TmuxHandler) GetGrouping(w http.ResponseWriter, r *http.Request) {
+	cfg := core.GetGroupingConfig()
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"rules":      cfg.Rules,
+		"priorities": cfg.Priorities,
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// PutGrouping handles PUT /api/tmux/grouping
+// Replaces the grouping rules and priorities, persists them and regroups the session list
+func (h *TmuxHandler) PutGrouping(w http.ResponseWriter, r *http.Request) {
+	var cfg core.GroupingConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = []core.GroupingRule{}
+	}
+	if valid, errMsg := core.ValidateGroupingConfig(cfg); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	if err := core.SaveConfigFile(core.GroupingFile, cfg); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	if err := core.SetGroupingConfig(cfg); err != nil {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	h.invalidateCache()
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"rules":      cfg.Rules,
+		"priorities": cfg.Priorities,
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// applyOptionGrouping regroups sessions using option grouping rules
+// Sessions pinned with @chrote-group keep their group
+func (h *TmuxHandler) applyOptionGrouping(sessions []core.Session) {
+	options := core.GroupingOptions()
+	if len(options) == 0 {
+		return
+	}
+
+	fields := append([]string{core.GroupOption}, options...)
+	format := "#{session_id}"
+	for _, option := range fields {
+		format += "\t#{" + option + "}"
+	}
+	output, err := h.runTmux("list-sessions", "-F", format)
+	if err != nil {
+		return
+	}
+
+	values := make(map[string]map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(parts) != len(fields)+1 {
+			continue
+		}
+		sessionValues := make(map[string]string, len(fields))
+		for i, option := range fields {
+			sessionValues[option] = parts[i+1]
+		}
+		values[parts[0]] = sessionValues
+	}
+
+	for i := range sessions {
+		sessionValues, ok := values[sessions[i].ID]
+		if !ok || sessionValues[core.GroupOption] != "" {
+			continue
+		}
+		sessions[i].Group = core.CategorizeSessionWithOptions(sessions[i].Name, sessionValues)
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chrote/server/internal/core"
+)
+
+func TestTmuxHandler_Grouping(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CHROTE_CONFIG_DIR", dir)
+	defer core.SetGroupingConfig(core.GroupingConfig{})
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	body := `{"rules":[{"type":"prefix","prefix":"web-"}],"priorities":{"web":2}}`
+	req := httptest.NewRequest(http.MethodPut, "/api/tmux/grouping", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if core.CategorizeSession("web-frontend") != "web" {
+		t.Error("Expected the new rule to be active")
+	}
+	if _, err := os.Stat(filepath.Join(dir, core.GroupingFile)); err != nil {
+		t.Errorf("Expected grouping to be persisted: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/tmux/grouping", nil)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	var got core.GroupingConfig
+	json.Unmarshal(rr.Body.Bytes(), &got)
+	if len(got.Rules) != 1 || got.Priorities["web"] != 2 {
+		t.Errorf("Unexpected GET response: %s", rr.Body.String())
+	}
+
+	// Reloading from disk restores the same rules
+	core.SetGroupingConfig(core.GroupingConfig{})
+	loadGrouping()
+	if core.CategorizeSession("web-frontend") != "web" {
+		t.Error("Expected loadGrouping to restore the persisted rule")
+	}
+}
+
+func TestTmuxHandler_PutGrouping_Invalid(t *testing.T) {
+	t.Setenv("CHROTE_CONFIG_DIR", t.TempDir())
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	for _, body := range []string{`{bad`, `{"rules":[{"type":"regex","pattern":"("}]}`} {
+		req := httptest.NewRequest(http.MethodPut, "/api/tmux/grouping", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s returned %d, expected 400", body, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+const (
+	// defaultPromptTimeout is how long waitForPrompt waits when no timeout is given
+	defaultPromptTimeout = 10 * time.Second
+	// maxPromptTimeout stays below the server's 30s write timeout
+	maxPromptTimeout = 25 * time.Second
+	// promptPollInterval is the delay between screen samples while waiting for a prompt
+	promptPollInterval = 250 * time.Millisecond
+)
+
+// SendInputRequest is the request body for sending input to a pane
+// Paste, Text, Keys and Enter are applied in that order
+type SendInputRequest struct {
+	Window        string   `json:"window,omitempty"`
+	Pane          string   `json:"pane,omitempty"`
+	Text          string   `json:"text,omitempty"`          // Literal text, never interpreted as key names
+	Keys          []string `json:"keys,omitempty"`          // Named keys, e.g. ["C-c"] or ["Escape"]
+	Paste         string   `json:"paste,omitempty"`         // Delivered through a tmux paste buffer
+	Enter         bool     `json:"enter,omitempty"`         // Press Enter after everything else
+	WaitForPrompt bool     `json:"waitForPrompt,omitempty"` // Block until the pane shows a prompt again
+	PromptPattern string   `json:"promptPattern,omitempty"` // Overrides core.PromptRegex
+	TimeoutMs     int      `json:"timeoutMs,omitempty"`
+}
+
+// SendInput handles POST /api/tmux/sessions/{name}/input
+func (h *TmuxHandler) SendInput(w http.ResponseWriter, r *http.Request) {
+	var req SendInputRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	target, errMsg := core.BuildTarget(r.PathValue("name"), req.Window, req.Pane)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	if req.Text == "" && req.Paste == "" && len(req.Keys) == 0 && !req.Enter {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Nothing to send. Provide text, keys, paste or enter.")
+		return
+	}
+	if valid, errMsg := core.ValidateInputText(req.Text, "text"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	if valid, errMsg := core.ValidateInputText(req.Paste, "paste"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	for _, key := range req.Keys {
+		if valid, errMsg := core.ValidateKeyName(key); !valid {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+			return
+		}
+	}
+
+	prompt := core.PromptRegex
+	if req.PromptPattern != "" {
+		compiled, err := regexp.Compile(req.PromptPattern)
+		if err != nil {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid promptPattern: "+err.Error())
+			return
+		}
+		prompt = compiled
+	}
+
+	timeout := defaultPromptTimeout
+	if req.TimeoutMs != 0 {
+		timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+		if timeout < 0 || timeout > maxPromptTimeout {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST",
+				"Invalid timeoutMs. Use a value between 0 and "+strconv.Itoa(int(maxPromptTimeout.Milliseconds()))+".")
+			return
+		}
+	}
+
+	if err := h.sendInput(target, req); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"target":    target,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if req.WaitForPrompt {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		found, screen := h.waitForPrompt(ctx, target, prompt)
+		response["prompt"] = found
+		response["screen"] = screen
+	}
+
+	core.WriteJSON(w, http.StatusOK, response)
+}
+
+// sendInput delivers the paste buffer, literal text, named keys and Enter to a target
+func (h *TmuxHandler) sendInput(target string, req SendInputRequest) error {
+	if req.Paste != "" {
+		buffer := "chrote-input-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+		if _, err := h.runTmuxWithInput(req.Paste, "load-buffer", "-b", buffer, "-"); err != nil {
+			return err
+		}
+		// -d deletes the buffer afterwards, -p uses bracketed paste when the app asked for it
+		if _, err := h.runTmux("paste-buffer", "-d", "-p", "-b", buffer, "-t", target); err != nil {
+			h.runTmux("delete-buffer", "-b", buffer)
+			return err
+		}
+	}
+
+	if req.Text != "" {
+		if _, err := h.runTmux("send-keys", "-t", target, "-l", "--", req.Text); err != nil {
+			return err
+		}
+	}
+
+	if len(req.Keys) > 0 {
+		args := append([]string{"send-keys", "-t", target}, req.Keys...)
+		if _, err := h.runTmux(args...); err != nil {
+			return err
+		}
+	}
+
+	if req.Enter {
+		if _, err := h.runTmux("send-keys", "-t", target, "Enter"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForPrompt polls the visible screen until its last line matches prompt and
+// the screen is stable across two samples, or until ctx expires
+func (h *TmuxHandler) waitForPrompt(ctx context.Context, target string, prompt *regexp.Regexp) (bool, string) {
+	previous := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return false, previous
+		case <-time.After(promptPollInterval):
+		}
+
+		screen, err := h.capturePane(target, 0, false, false)
+		if err != nil {
+			return false, previous
+		}
+		if screen == previous && prompt.MatchString(core.LastNonEmptyLine(screen)) {
+			return true, screen
+		}
+		previous = screen
+	}
+}
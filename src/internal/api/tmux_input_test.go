@@ -0,0 +1,42 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTmuxHandler_SendInput_Validation(t *testing.T) {
+	handler := NewTmuxHandler()
+
+	tests := []struct {
+		name    string
+		session string
+		body    string
+	}{
+		{"invalid JSON", "main", "{invalid}"},
+		{"invalid session", "bad@name", `{"text":"ls"}`},
+		{"invalid pane target", "main", `{"pane":"1","text":"ls"}`},
+		{"empty payload", "main", `{}`},
+		{"bad key name", "main", `{"keys":["Enter; kill-server"]}`},
+		{"nul in text", "main", `{"text":"a\u0000b"}`},
+		{"bad prompt pattern", "main", `{"text":"ls","waitForPrompt":true,"promptPattern":"("}`},
+		{"timeout too long", "main", `{"text":"ls","waitForPrompt":true,"timeoutMs":600000}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/tmux/sessions/"+tt.session+"/input", bytes.NewBufferString(tt.body))
+			req.SetPathValue("name", tt.session)
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+
+			handler.SendInput(recorder, req)
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("Status code = %d, expected %d", recorder.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
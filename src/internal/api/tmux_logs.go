@@ -0,0 +1,407 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+const (
+	// maxLogBytes is the size at which a session's current log is rotated
+	maxLogBytes = 10 * 1024 * 1024
+	// maxLogArchives is how many rotated logs are kept per session
+	maxLogArchives = 10
+	// logRotateInterval is how often current logs are checked for rotation
+	logRotateInterval = time.Minute
+)
+
+// EnableLoggingRequest is the request body for enabling session logging
+type EnableLoggingRequest struct {
+	Window string `json:"window,omitempty"` // Defaults to the active window
+	Pane   string `json:"pane,omitempty"`   // Defaults to the active pane
+}
+
+// LogSessionInfo summarizes the logs of one session
+type LogSessionInfo struct {
+	Session  string `json:"session"`
+	Files    int    `json:"files"`
+	Size     int64  `json:"size"`
+	Modified string `json:"modified,omitempty"`
+	Logging  bool   `json:"logging"` // Session exists and is still being logged
+}
+
+// shellQuote quotes s for use as a single word in a sh command line
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// pipeState is where a session sends pane output: the logged pane and the recorded pane and its cast file
+type pipeState struct {
+	logPane  string
+	recPane  string
+	recFile  string
+	exe      string // this server's executable, which records casts (see core.RecordCast)
+	geometry string // "width height" of the pane being piped
+}
+
+// pipeCommand returns the pipe-pane command for a pane, or "" when its output goes nowhere
+// A pane that is both logged and recorded tees its output to the log and the recorder
+func pipeCommand(session, paneID string, st pipeState) string {
+	var logCmd, recCmd string
+	if st.logPane == paneID {
+		logCmd = shellQuote(filepath.Join(core.SessionLogDir(session), core.CurrentLogFile))
+	}
+	if st.recPane == paneID && st.recFile != "" {
+		recCmd = shellQuote(st.exe) + " record-cast " +
+			shellQuote(filepath.Join(core.SessionRecordingDir(session), st.recFile)) + " " +
+			st.geometry + " " + shellQuote(session)
+	}
+
+	switch {
+	case logCmd != "" && recCmd != "":
+		return "tee -a " + logCmd + " | " + recCmd
+	case logCmd != "":
+		return "cat >> " + logCmd
+	case recCmd != "":
+		return "exec " + recCmd
+	}
+	return ""
+}
+
+// repipe starts, replaces or closes a pane's pipe to match its session's logging and recording
+func (h *TmuxHandler) repipe(session, paneID string) error {
+	output, err := h.runTmux("display-message", "-p", "-t", paneID,
+		"#{"+core.LoggingOption+"}\t#{"+core.RecordingOption+"}\t#{pane_width} #{pane_height}")
+	if err != nil {
+		return err
+	}
+	parts := strings.SplitN(strings.TrimRight(output, "\r\n"), "\t", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("unexpected pane info: %q", output)
+	}
+
+	st := pipeState{logPane: parts[0], geometry: parts[2]}
+	st.recPane, st.recFile = core.ParseRecordingOption(parts[1])
+	if st.recPane != "" {
+		if st.exe, err = os.Executable(); err != nil {
+			return err
+		}
+	}
+
+	if command := pipeCommand(session, paneID, st); command != "" {
+		_, err = h.runTmux("pipe-pane", "-t", paneID, command)
+	} else {
+		_, err = h.runTmux("pipe-pane", "-t", paneID)
+	}
+	return err
+}
+
+// resolvePane returns the ID of a session's pane, the active one unless window and pane are given
+// Returns a validation message for a bad target or the tmux error for a missing one
+func (h *TmuxHandler) resolvePane(session, window, pane string) (string, string, error) {
+	target, errMsg := core.BuildTarget(session, window, pane)
+	if errMsg != "" {
+		return "", errMsg, nil
+	}
+	output, err := h.runTmux("display-message", "-p", "-t", target, "#{pane_id}")
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(output), "", nil
+}
+
+// loggedPanes returns the ID of the logged pane for every session with logging enabled
+func (h *TmuxHandler) loggedPanes() map[string]string {
+	panes := make(map[string]string)
+	output, err := h.runTmux("list-sessions", "-F", "#{session_name}\t#{"+core.LoggingOption+"}")
+	if err != nil {
+		return panes
+	}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		name, pane, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if pane != "" {
+			panes[name] = pane
+		}
+	}
+	return panes
+}
+
+// EnableLogging handles POST /api/tmux/sessions/{name}/logging
+// Pipes one pane's output (the active one by default) to the session's current log
+func (h *TmuxHandler) EnableLogging(w http.ResponseWriter, r *http.Request) {
+	sessionName := r.PathValue("name")
+
+	var req EnableLoggingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength > 0 {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	paneID, errMsg, err := h.resolvePane(sessionName, req.Window, req.Pane)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	if err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	if err := os.MkdirAll(core.SessionLogDir(sessionName), 0700); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	previous := h.loggedPanes()[sessionName]
+	if _, err := h.runTmux("set-option", "-t", "="+sessionName+":", core.LoggingOption, paneID); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+	if err := h.repipe(sessionName, paneID); err != nil {
+		h.runTmux("set-option", "-u", "-t", "="+sessionName+":", core.LoggingOption)
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+	// Moving the log to another pane leaves the old one with at most its recording
+	if previous != "" && previous != paneID {
+		h.repipe(sessionName, previous)
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"session":   sessionName,
+		"pane":      paneID,
+		"file":      filepath.Join(core.SessionLogDir(sessionName), core.CurrentLogFile),
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// DisableLogging handles DELETE /api/tmux/sessions/{name}/logging
+// Existing logs are kept
+func (h *TmuxHandler) DisableLogging(w http.ResponseWriter, r *http.Request) {
+	sessionName := r.PathValue("name")
+	if valid, errMsg := core.ValidateSessionName(sessionName, "session name"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	paneID := h.loggedPanes()[sessionName]
+	if paneID == "" {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Session "+sessionName+" is not being logged")
+		return
+	}
+
+	if _, err := h.runTmux("set-option", "-u", "-t", "="+sessionName+":", core.LoggingOption); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+	// The pane may be gone already, which closed its pipe anyway
+	h.repipe(sessionName, paneID)
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"session":   sessionName,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// ListLogs handles GET /api/tmux/logs
+// Lists every session with logs, including sessions that no longer exist
+func (h *TmuxHandler) ListLogs(w http.ResponseWriter, r *http.Request) {
+	sessions, err := core.ListLogSessions()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	logged := h.loggedPanes()
+	infos := make([]LogSessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		files, err := core.ListLogFiles(session)
+		if err != nil {
+			continue
+		}
+		info := LogSessionInfo{Session: session, Files: len(files), Logging: logged[session] != ""}
+		for _, file := range files {
+			info.Size += file.Size
+			if file.Modified > info.Modified {
+				info.Modified = file.Modified
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"logDir":    core.GetLogDir(),
+		"sessions":  infos,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// ListSessionLogs handles GET /api/tmux/logs/{session}
+func (h *TmuxHandler) ListSessionLogs(w http.ResponseWriter, r *http.Request) {
+	session := r.PathValue("session")
+	if valid, errMsg := core.ValidateSessionName(session, "session name"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	files, err := core.ListLogFiles(session)
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	if len(files) == 0 {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "No logs for session "+session)
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"session":   session,
+		"files":     files,
+		"logging":   h.loggedPanes()[session] != "",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// SearchSessionLogs handles GET /api/tmux/logs/{session}/search
+// Query: q (required), regex (1 to treat q as a regular expression), icase (1 for case-insensitive), limit
+func (h *TmuxHandler) SearchSessionLogs(w http.ResponseWriter, r *http.Request) {
+	session := r.PathValue("session")
+	if valid, errMsg := core.ValidateSessionName(session, "session name"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	query := r.URL.Query()
+	pattern, errMsg := searchPattern(query)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	limit, errMsg := intParam(query, "limit", defaultSearchLimit, 1, maxSearchLimit)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	matches, truncated, err := core.SearchLogs(session, pattern, limit)
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"session":   session,
+		"matches":   matches,
+		"truncated": truncated,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// DownloadSessionLog handles GET /api/tmux/logs/{session}/files/{file}
+// Query: plain (1 to strip terminal escape sequences)
+func (h *TmuxHandler) DownloadSessionLog(w http.ResponseWriter, r *http.Request) {
+	session := r.PathValue("session")
+	if valid, errMsg := core.ValidateSessionName(session, "session name"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	file := r.PathValue("file")
+	if !core.LogFileRegex.MatchString(file) {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid log file name")
+		return
+	}
+
+	path := filepath.Join(core.SessionLogDir(session), file)
+	if !core.FileExists(path) {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Log file not found: "+file)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+session+"-"+file+"\"")
+	if r.URL.Query().Get("plain") != "1" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		http.ServeFile(w, r, path)
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(core.StripANSI(string(data))))
+}
+
+// logRotator periodically rotates session logs that have grown too large
+type logRotator struct {
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running bool
+	stop    chan struct{}
+}
+
+// Start starts rotating in the background
+func (lr *logRotator) Start() {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	if lr.running {
+		return
+	}
+	lr.running = true
+	lr.stop = make(chan struct{})
+
+	lr.wg.Add(1)
+	go lr.run(lr.stop)
+}
+
+// Stop stops rotating
+func (lr *logRotator) Stop() {
+	lr.mu.Lock()
+	if !lr.running {
+		lr.mu.Unlock()
+		return
+	}
+	lr.running = false
+	close(lr.stop)
+	lr.mu.Unlock()
+
+	lr.wg.Wait()
+}
+
+// run checks every session's current log on a fixed interval until stop closes
+func (lr *logRotator) run(stop chan struct{}) {
+	defer lr.wg.Done()
+
+	ticker := time.NewTicker(logRotateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		sessions, err := core.ListLogSessions()
+		if err != nil {
+			continue
+		}
+		for _, session := range sessions {
+			if _, err := core.RotateLog(session, maxLogBytes, maxLogArchives, time.Now()); err != nil {
+				log.Printf("Log rotation failed for %s: %v", session, err)
+			}
+		}
+	}
+}
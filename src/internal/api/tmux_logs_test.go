@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chrote/server/internal/core"
+)
+
+func TestTmuxHandler_Logs_Validation(t *testing.T) {
+	t.Setenv("CHROTE_LOG_DIR", t.TempDir())
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"enable invalid name", http.MethodPost, "/api/tmux/sessions/bad@name/logging", http.StatusBadRequest},
+		{"disable invalid name", http.MethodDelete, "/api/tmux/sessions/bad@name/logging", http.StatusBadRequest},
+		{"list invalid session", http.MethodGet, "/api/tmux/logs/bad@name", http.StatusBadRequest},
+		{"list unknown session", http.MethodGet, "/api/tmux/logs/nologs", http.StatusNotFound},
+		{"search missing query", http.MethodGet, "/api/tmux/logs/dev/search", http.StatusBadRequest},
+		{"search invalid regex", http.MethodGet, "/api/tmux/logs/dev/search?q=(&regex=1", http.StatusBadRequest},
+		{"search invalid limit", http.MethodGet, "/api/tmux/logs/dev/search?q=x&limit=0", http.StatusBadRequest},
+		{"download invalid file", http.MethodGet, "/api/tmux/logs/dev/files/passwd", http.StatusBadRequest},
+		{"download missing file", http.MethodGet, "/api/tmux/logs/dev/files/20260101-000000.log", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestTmuxHandler_DownloadSessionLog_Plain(t *testing.T) {
+	t.Setenv("CHROTE_LOG_DIR", t.TempDir())
+	dir := core.SessionLogDir("dev")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, core.CurrentLogFile), []byte("\x1b[32mok\x1b[0m\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tmux/logs/dev/files/current.log?plain=1", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Body.String() != "ok\n" {
+		t.Errorf("Expected stripped log, got %q", rr.Body.String())
+	}
+	if !strings.Contains(rr.Header().Get("Content-Disposition"), "dev-current.log") {
+		t.Errorf("Unexpected Content-Disposition: %s", rr.Header().Get("Content-Disposition"))
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("/tmp/it's here"); got != `'/tmp/it'\''s here'` {
+		t.Errorf("Unexpected quoting: %s", got)
+	}
+}
@@ -0,0 +1,247 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"hash/fnv"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// monitorInterval is how often the session monitor samples every session's active pane
+const monitorInterval = 3 * time.Second
+
+// monitorPaneFormat lists every pane with what the monitor needs to find each session's active one
+const monitorPaneFormat = "#{session_id}\t#{window_active}\t#{pane_active}\t#{pane_id}\t#{pane_dead}\t" +
+	"#{window_activity}\t#{pane_current_command}"
+
+// monitorPaneFields is the number of fields in monitorPaneFormat
+const monitorPaneFields = 7
+
+// activePane is the active pane of a session's current window
+type activePane struct {
+	ID       string
+	Dead     bool
+	Activity string
+	Command  string
+}
+
+// monitoredSession is what the monitor remembers about a session between samples
+type monitoredSession struct {
+	hash        uint64
+	activity    string
+	command     string
+	agentExited bool
+	state       string
+	since       time.Time
+}
+
+// sessionMonitor samples each session's active pane and classifies it as
+// working, idle, waiting for input or crashed (see core.ClassifySession)
+type sessionMonitor struct {
+	handler *TmuxHandler
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	running  bool
+	stop     chan struct{}
+	sessions map[string]*monitoredSession // keyed by tmux session ID
+}
+
+// newSessionMonitor creates a monitor for the given handler
+func newSessionMonitor(h *TmuxHandler) *sessionMonitor {
+	return &sessionMonitor{
+		handler:  h,
+		sessions: make(map[string]*monitoredSession),
+	}
+}
+
+// Start starts sampling in the background
+func (m *sessionMonitor) Start() {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = true
+	m.stop = make(chan struct{})
+	stop := m.stop
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go m.run(stop)
+	log.Printf("Session monitor started")
+}
+
+// Stop stops sampling and forgets all session states
+func (m *sessionMonitor) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	close(m.stop)
+	m.mu.Unlock()
+
+	m.wg.Wait()
+
+	m.mu.Lock()
+	m.sessions = make(map[string]*monitoredSession)
+	m.mu.Unlock()
+}
+
+// run samples on a fixed interval until stop closes
+func (m *sessionMonitor) run(stop chan struct{}) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+
+	for {
+		m.sample()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample takes one observation of every session and publishes state changes
+func (m *sessionMonitor) sample() {
+	output, err := m.handler.runTmux("list-panes", "-a", "-F", monitorPaneFormat)
+	if err != nil {
+		// No server means no sessions, anything else is worth retrying next tick
+		if strings.Contains(err.Error(), "no server running") {
+			m.mu.Lock()
+			m.sessions = make(map[string]*monitoredSession)
+			m.mu.Unlock()
+		}
+		return
+	}
+	panes := parseActivePanes(output)
+
+	screens := make(map[string]string, len(panes))
+	for sessionID, pane := range panes {
+		if pane.Dead {
+			continue
+		}
+		screen, err := m.handler.runTmux("capture-pane", "-p", "-t", pane.ID)
+		if err != nil {
+			continue
+		}
+		screens[sessionID] = screen
+	}
+
+	var changed []Event
+	now := time.Now()
+
+	m.mu.Lock()
+	for sessionID := range m.sessions {
+		if _, ok := panes[sessionID]; !ok {
+			delete(m.sessions, sessionID)
+		}
+	}
+	for sessionID, pane := range panes {
+		screen, captured := screens[sessionID]
+		if !captured && !pane.Dead {
+			continue
+		}
+		hasher := fnv.New64a()
+		hasher.Write([]byte(screen))
+		hash := hasher.Sum64()
+
+		prev, known := m.sessions[sessionID]
+		next := &monitoredSession{
+			hash:     hash,
+			activity: pane.Activity,
+			command:  pane.Command,
+		}
+		if known {
+			next.agentExited = prev.agentExited
+			if core.AgentCommands[prev.command] && core.ShellCommands[pane.Command] {
+				next.agentExited = true
+			} else if !core.ShellCommands[pane.Command] {
+				next.agentExited = false
+			}
+		}
+
+		next.state = core.ClassifySession(core.StateSample{
+			Screen:      screen,
+			Command:     pane.Command,
+			Dead:        pane.Dead,
+			Changed:     known && (prev.hash != hash || prev.activity != pane.Activity),
+			AgentExited: next.agentExited,
+		})
+
+		next.since = now
+		if known && prev.state == next.state {
+			next.since = prev.since
+		} else {
+			previous := ""
+			if known {
+				previous = prev.state
+			}
+			changed = append(changed, NewEvent(EventSessionState, "", map[string]interface{}{
+				"id":       sessionID,
+				"state":    next.state,
+				"previous": previous,
+				"command":  pane.Command,
+			}))
+		}
+		m.sessions[sessionID] = next
+	}
+	m.mu.Unlock()
+
+	if len(changed) == 0 {
+		return
+	}
+
+	// Re-list so the cached sessions carry the new states, then name the sessions in the events
+	m.handler.invalidateCache()
+	names := make(map[string]string)
+	for _, s := range m.handler.sessions().Sessions {
+		names[s.ID] = s.Name
+	}
+	for _, event := range changed {
+		event.Session = names[event.Data["id"].(string)]
+		m.handler.events.Publish(event)
+	}
+}
+
+// annotate fills in State and StateSince for sessions the monitor has sampled
+func (m *sessionMonitor) annotate(sessions []core.Session) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range sessions {
+		if s, ok := m.sessions[sessions[i].ID]; ok {
+			sessions[i].State = s.state
+			sessions[i].StateSince = s.since.UTC().Format(time.RFC3339)
+		}
+	}
+}
+
+// parseActivePanes parses list-panes -a output in monitorPaneFormat into
+// the active pane of each session's current window, keyed by session ID
+func parseActivePanes(output string) map[string]activePane {
+	panes := make(map[string]activePane)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		parts := strings.SplitN(line, "\t", monitorPaneFields)
+		if len(parts) != monitorPaneFields || parts[1] != "1" || parts[2] != "1" {
+			continue
+		}
+		panes[parts[0]] = activePane{
+			ID:       parts[3],
+			Dead:     parts[4] == "1",
+			Activity: parts[5],
+			Command:  parts[6],
+		}
+	}
+	return panes
+}
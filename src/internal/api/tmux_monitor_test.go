@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/chrote/server/internal/core"
+)
+
+func TestParseActivePanes(t *testing.T) {
+	output := "$1\t1\t0\t%1\t0\t1700000000\tbash\n" +
+		"$1\t1\t1\t%2\t0\t1700000005\tclaude\n" +
+		"$1\t0\t1\t%3\t0\t1700000001\tvim\n" +
+		"$2\t1\t1\t%4\t1\t1700000002\tnode\n" +
+		"garbage\n"
+
+	panes := parseActivePanes(output)
+
+	if len(panes) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d: %v", len(panes), panes)
+	}
+	if p := panes["$1"]; p.ID != "%2" || p.Command != "claude" || p.Activity != "1700000005" || p.Dead {
+		t.Errorf("Unexpected active pane for $1: %+v", p)
+	}
+	if p := panes["$2"]; p.ID != "%4" || !p.Dead {
+		t.Errorf("Unexpected active pane for $2: %+v", p)
+	}
+}
+
+func TestSessionMonitor_Annotate(t *testing.T) {
+	handler := NewTmuxHandler()
+	handler.monitor.sessions["$1"] = &monitoredSession{state: core.StateWaiting}
+
+	sessions := []core.Session{{ID: "$1", Name: "one"}, {ID: "$2", Name: "two"}}
+	handler.monitor.annotate(sessions)
+
+	if sessions[0].State != core.StateWaiting || sessions[0].StateSince == "" {
+		t.Errorf("Expected $1 to be annotated, got %+v", sessions[0])
+	}
+	if sessions[1].State != "" {
+		t.Errorf("Expected $2 to be left alone, got %+v", sessions[1])
+	}
+}
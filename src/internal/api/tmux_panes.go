@@ -0,0 +1,308 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// CreateWindowRequest is the request body for creating a window
+type CreateWindowRequest struct {
+	Name    string `json:"name,omitempty"`
+	Cwd     string `json:"cwd,omitempty"`
+	Command string `json:"command,omitempty"` // Runs instead of the default shell
+	Select  bool   `json:"select,omitempty"`  // Make the new window current
+}
+
+// RenameWindowRequest is the request body for renaming a window
+type RenameWindowRequest struct {
+	NewName string `json:"newName"`
+}
+
+// SplitPaneRequest is the request body for splitting a pane
+type SplitPaneRequest struct {
+	Pane      string `json:"pane,omitempty"`      // Pane to split, defaults to the active pane
+	Direction string `json:"direction,omitempty"` // "vertical" (stacked, default) or "horizontal" (side by side)
+	Size      int    `json:"size,omitempty"`      // Size of the new pane in percent
+	Cwd       string `json:"cwd,omitempty"`
+	Command   string `json:"command,omitempty"`
+	Select    bool   `json:"select,omitempty"` // Make the new pane active
+}
+
+// listWindows returns the windows and panes of a session
+func (h *TmuxHandler) listWindows(session string) ([]core.Window, error) {
+	output, err := h.runTmux("list-panes", "-s", "-t", "="+session+":", "-F", core.PaneFormat)
+	if err != nil {
+		return nil, err
+	}
+	return core.ParsePanes(output), nil
+}
+
+// validateSpawnOptions validates the working directory and command for a new window or pane
+// Returns the extra tmux arguments (-c dir) and the trailing command, or an error message
+func validateSpawnOptions(cwd, command string) ([]string, []string, string, string) {
+	var args, trailing []string
+	if cwd != "" {
+		resolved, code, msg := core.ValidateProjectPath(cwd)
+		if code != "" {
+			return nil, nil, code, msg
+		}
+		args = append(args, "-c", resolved)
+	}
+	if command != "" {
+		if valid, errMsg := core.ValidateInputText(command, "command"); !valid {
+			return nil, nil, "BAD_REQUEST", errMsg
+		}
+		trailing = append(trailing, command)
+	}
+	return args, trailing, "", ""
+}
+
+// ListWindows handles GET /api/tmux/sessions/{name}/windows
+func (h *TmuxHandler) ListWindows(w http.ResponseWriter, r *http.Request) {
+	sessionName := r.PathValue("name")
+	if valid, errMsg := core.ValidateSessionName(sessionName, "session name"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	windows, err := h.listWindows(sessionName)
+	if err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"session":   sessionName,
+		"windows":   windows,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// CreateWindow handles POST /api/tmux/sessions/{name}/windows
+func (h *TmuxHandler) CreateWindow(w http.ResponseWriter, r *http.Request) {
+	sessionName := r.PathValue("name")
+	if valid, errMsg := core.ValidateSessionName(sessionName, "session name"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	var req CreateWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength > 0 {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	args := []string{"new-window", "-P", "-F", "#{window_index}", "-t", "=" + sessionName + ":"}
+	if !req.Select {
+		args = append(args, "-d")
+	}
+	if req.Name != "" {
+		if valid, errMsg := core.ValidateWindowName(req.Name); !valid {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+			return
+		}
+		args = append(args, "-n", req.Name)
+	}
+
+	spawnArgs, command, code, msg := validateSpawnOptions(req.Cwd, req.Command)
+	if code != "" {
+		core.WriteError(w, core.GetErrorStatusCode(code), code, msg)
+		return
+	}
+	args = append(append(args, spawnArgs...), command...)
+
+	output, err := h.runTmux(args...)
+	if err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	h.invalidateCache()
+
+	index, _ := strconv.Atoi(strings.TrimSpace(output))
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"session":   sessionName,
+		"window":    index,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// RenameWindow handles PATCH /api/tmux/sessions/{name}/windows/{window}
+func (h *TmuxHandler) RenameWindow(w http.ResponseWriter, r *http.Request) {
+	target, errMsg := core.BuildTarget(r.PathValue("name"), r.PathValue("window"), "")
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	var req RenameWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+	if valid, errMsg := core.ValidateWindowName(req.NewName); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	if _, err := h.runTmux("rename-window", "-t", target, req.NewName); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"target":    target,
+		"newName":   req.NewName,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// DeleteWindow handles DELETE /api/tmux/sessions/{name}/windows/{window}
+func (h *TmuxHandler) DeleteWindow(w http.ResponseWriter, r *http.Request) {
+	target, errMsg := core.BuildTarget(r.PathValue("name"), r.PathValue("window"), "")
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	if _, err := h.runTmux("kill-window", "-t", target); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	h.invalidateCache()
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"killed":    target,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// SelectWindow handles POST /api/tmux/sessions/{name}/windows/{window}/select
+func (h *TmuxHandler) SelectWindow(w http.ResponseWriter, r *http.Request) {
+	target, errMsg := core.BuildTarget(r.PathValue("name"), r.PathValue("window"), "")
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	if _, err := h.runTmux("select-window", "-t", target); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"selected":  target,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// SplitPane handles POST /api/tmux/sessions/{name}/windows/{window}/split
+func (h *TmuxHandler) SplitPane(w http.ResponseWriter, r *http.Request) {
+	var req SplitPaneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength > 0 {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	target, errMsg := core.BuildTarget(r.PathValue("name"), r.PathValue("window"), req.Pane)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	args := []string{"split-window", "-P", "-F", "#{pane_index}", "-t", target}
+	switch req.Direction {
+	case "", "vertical":
+		args = append(args, "-v")
+	case "horizontal":
+		args = append(args, "-h")
+	default:
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid direction. Use vertical or horizontal.")
+		return
+	}
+	if req.Size != 0 {
+		if req.Size < 1 || req.Size > 99 {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid size. Use a percentage between 1 and 99.")
+			return
+		}
+		args = append(args, "-l", strconv.Itoa(req.Size)+"%")
+	}
+	if !req.Select {
+		args = append(args, "-d")
+	}
+
+	spawnArgs, command, code, msg := validateSpawnOptions(req.Cwd, req.Command)
+	if code != "" {
+		core.WriteError(w, core.GetErrorStatusCode(code), code, msg)
+		return
+	}
+	args = append(append(args, spawnArgs...), command...)
+
+	output, err := h.runTmux(args...)
+	if err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	index, _ := strconv.Atoi(strings.TrimSpace(output))
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"target":    target,
+		"pane":      index,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// SelectPane handles POST /api/tmux/sessions/{name}/windows/{window}/panes/{pane}/select
+func (h *TmuxHandler) SelectPane(w http.ResponseWriter, r *http.Request) {
+	target, errMsg := core.BuildTarget(r.PathValue("name"), r.PathValue("window"), r.PathValue("pane"))
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	if _, err := h.runTmux("select-pane", "-t", target); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"selected":  target,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// DeletePane handles DELETE /api/tmux/sessions/{name}/windows/{window}/panes/{pane}
+func (h *TmuxHandler) DeletePane(w http.ResponseWriter, r *http.Request) {
+	target, errMsg := core.BuildTarget(r.PathValue("name"), r.PathValue("window"), r.PathValue("pane"))
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	if _, err := h.runTmux("kill-pane", "-t", target); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	// Killing the last pane closes the window
+	h.invalidateCache()
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"killed":    target,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTmuxHandler_WindowEndpoints_Validation(t *testing.T) {
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"list invalid session", http.MethodGet, "/api/tmux/sessions/bad@name/windows", ""},
+		{"create invalid window name", http.MethodPost, "/api/tmux/sessions/main/windows", `{"name":"bad;name"}`},
+		{"create cwd outside roots", http.MethodPost, "/api/tmux/sessions/main/windows", `{"cwd":"/etc"}`},
+		{"rename non-numeric window", http.MethodPatch, "/api/tmux/sessions/main/windows/abc", `{"newName":"x"}`},
+		{"rename invalid new name", http.MethodPatch, "/api/tmux/sessions/main/windows/1", `{"newName":""}`},
+		{"kill non-numeric window", http.MethodDelete, "/api/tmux/sessions/main/windows/abc", ""},
+		{"split bad direction", http.MethodPost, "/api/tmux/sessions/main/windows/0/split", `{"direction":"diagonal"}`},
+		{"split bad size", http.MethodPost, "/api/tmux/sessions/main/windows/0/split", `{"size":150}`},
+		{"select non-numeric pane", http.MethodPost, "/api/tmux/sessions/main/windows/0/panes/x/select", ""},
+		{"kill non-numeric pane", http.MethodDelete, "/api/tmux/sessions/main/windows/0/panes/x", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+
+			mux.ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusBadRequest && recorder.Code != http.StatusForbidden {
+				t.Errorf("Status code = %d, expected a client error", recorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// isForced reports whether the request asked to override session protection (?force=1)
+func isForced(r *http.Request) bool {
+	force := r.URL.Query().Get("force")
+	return force == "1" || force == "true"
+}
+
+// isProtected reports whether a session refuses to be killed without force
+func (h *TmuxHandler) isProtected(name string) bool {
+	if protectedSessions[name] {
+		return true
+	}
+	output, err := h.runTmux("show-options", "-qv", "-t", "="+name+":", core.ProtectedOption)
+	return err == nil && strings.TrimSpace(output) == "1"
+}
+
+// auditForced records that a protected session was acted on with force
+func auditForced(r *http.Request, action, session string) {
+	log.Printf("AUDIT: forced %s of protected session %q from %s", action, session, r.RemoteAddr)
+	err := core.AppendAudit(core.AuditEntry{
+		Action:  "force-" + action,
+		Session: session,
+		Remote:  r.RemoteAddr,
+		Detail:  r.Method + " " + r.URL.Path,
+	})
+	if err != nil {
+		log.Printf("AUDIT: failed to write audit log: %v", err)
+	}
+}
+
+// ProtectSession handles POST /api/tmux/sessions/{name}/protect
+// Protection is stored as a tmux user option, so it lives exactly as long as the session
+func (h *TmuxHandler) ProtectSession(w http.ResponseWriter, r *http.Request) {
+	h.setProtected(w, r, true)
+}
+
+// UnprotectSession handles DELETE /api/tmux/sessions/{name}/protect
+func (h *TmuxHandler) UnprotectSession(w http.ResponseWriter, r *http.Request) {
+	h.setProtected(w, r, false)
+}
+
+// setProtected sets or clears a session's protected option
+func (h *TmuxHandler) setProtected(w http.ResponseWriter, r *http.Request, protected bool) {
+	sessionName := r.PathValue("name")
+	if valid, errMsg := core.ValidateSessionName(sessionName, "session name"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	if !protected && protectedSessions[sessionName] {
+		core.WriteError(w, http.StatusForbidden, "FORBIDDEN", "Session "+sessionName+" is always protected.")
+		return
+	}
+
+	args := []string{"set-option", "-t", "=" + sessionName + ":", core.ProtectedOption, "1"}
+	if !protected {
+		args = []string{"set-option", "-u", "-t", "=" + sessionName + ":", core.ProtectedOption}
+	}
+	if _, err := h.runTmux(args...); err != nil {
+		status := tmuxErrorStatus(err)
+		if strings.Contains(err.Error(), "no such session") {
+			status = http.StatusNotFound
+		}
+		core.WriteError(w, status, "TMUX_ERROR", err.Error())
+		return
+	}
+
+	h.invalidateCache()
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"session":   sessionName,
+		"protected": protected,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTmuxHandler_Protect_Validation(t *testing.T) {
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"protect invalid name", http.MethodPost, "/api/tmux/sessions/bad@name/protect", http.StatusBadRequest},
+		{"unprotect invalid name", http.MethodDelete, "/api/tmux/sessions/bad@name/protect", http.StatusBadRequest},
+		{"unprotect built-in", http.MethodDelete, "/api/tmux/sessions/chrote-chat/protect", http.StatusForbidden},
+		{"kill built-in without force", http.MethodDelete, "/api/tmux/sessions/chrote-chat", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestIsForced(t *testing.T) {
+	tests := []struct {
+		query  string
+		forced bool
+	}{
+		{"", false},
+		{"?force=1", true},
+		{"?force=true", true},
+		{"?force=0", false},
+		{"?force=yes", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodDelete, "/api/tmux/sessions/x"+tt.query, nil)
+		if got := isForced(req); got != tt.forced {
+			t.Errorf("isForced(%q) = %v, expected %v", tt.query, got, tt.forced)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// StartRecordingRequest is the request body for starting a session recording
+type StartRecordingRequest struct {
+	Window string `json:"window,omitempty"` // Defaults to the active window
+	Pane   string `json:"pane,omitempty"`   // Defaults to the active pane
+}
+
+// recordingOf returns the recorded pane and cast file of a session, if it is being recorded
+func (h *TmuxHandler) recordingOf(session string) (string, string) {
+	output, err := h.runTmux("show-options", "-qv", "-t", "="+session+":", core.RecordingOption)
+	if err != nil {
+		return "", ""
+	}
+	return core.ParseRecordingOption(output)
+}
+
+// StartRecording handles POST /api/tmux/sessions/{name}/recording
+// Records one pane's output (the active one by default) to a new asciicast v2 file
+func (h *TmuxHandler) StartRecording(w http.ResponseWriter, r *http.Request) {
+	sessionName := r.PathValue("name")
+
+	var req StartRecordingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength > 0 {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	paneID, errMsg, err := h.resolvePane(sessionName, req.Window, req.Pane)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	if err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	if pane, file := h.recordingOf(sessionName); pane != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST",
+			"Session "+sessionName+" is already being recorded to "+file+". Stop that recording first.")
+		return
+	}
+
+	if err := os.MkdirAll(core.SessionRecordingDir(sessionName), 0700); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	file := core.NewCastName(time.Now())
+	if _, err := h.runTmux("set-option", "-t", "="+sessionName+":", core.RecordingOption, paneID+" "+file); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+	if err := h.repipe(sessionName, paneID); err != nil {
+		h.runTmux("set-option", "-u", "-t", "="+sessionName+":", core.RecordingOption)
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"session":   sessionName,
+		"pane":      paneID,
+		"recording": file,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// StopRecording handles DELETE /api/tmux/sessions/{name}/recording
+func (h *TmuxHandler) StopRecording(w http.ResponseWriter, r *http.Request) {
+	sessionName := r.PathValue("name")
+	if valid, errMsg := core.ValidateSessionName(sessionName, "session name"); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	paneID, file := h.recordingOf(sessionName)
+	if paneID == "" {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Session "+sessionName+" is not being recorded")
+		return
+	}
+
+	if _, err := h.runTmux("set-option", "-u", "-t", "="+sessionName+":", core.RecordingOption); err != nil {
+		core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+		return
+	}
+	// Leaves the pane with at most its log; the recorder exits when its input closes
+	h.repipe(sessionName, paneID)
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"session":   sessionName,
+		"recording": file,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// ListRecordings handles GET /api/tmux/recordings
+// Query: session (only that session's recordings)
+func (h *TmuxHandler) ListRecordings(w http.ResponseWriter, r *http.Request) {
+	session := r.URL.Query().Get("session")
+	if session != "" {
+		if valid, errMsg := core.ValidateSessionName(session, "session"); !valid {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+			return
+		}
+	}
+
+	recordings, err := core.ListRecordings()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	// Recordings still being written are flagged so players know the cast may grow
+	active := make(map[string]bool)
+	if output, err := h.runTmux("list-sessions", "-F", "#{session_name}\t#{"+core.RecordingOption+"}"); err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			name, value, _ := strings.Cut(strings.TrimSpace(line), "\t")
+			if _, file := core.ParseRecordingOption(value); file != "" {
+				active[name+"/"+file] = true
+			}
+		}
+	}
+
+	result := make([]core.RecordingInfo, 0, len(recordings))
+	for _, rec := range recordings {
+		if session != "" && rec.Session != session {
+			continue
+		}
+		rec.Active = active[rec.Session+"/"+rec.Name]
+		result = append(result, rec)
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"recordingsDir": core.GetRecordingsDir(),
+		"recordings":    result,
+		"timestamp":     time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// recordingPath validates the session and file path values and returns the cast file's path
+func recordingPath(r *http.Request) (string, string) {
+	session := r.PathValue("session")
+	if valid, errMsg := core.ValidateSessionName(session, "session name"); !valid {
+		return "", errMsg
+	}
+	file := r.PathValue("file")
+	if !core.CastFileRegex.MatchString(file) {
+		return "", "Invalid recording name"
+	}
+	return filepath.Join(core.SessionRecordingDir(session), file), ""
+}
+
+// GetRecording handles GET /api/tmux/recordings/{session}/{file}
+// Serves the asciicast v2 file for playback (e.g. with asciinema-player); download=1 saves it instead
+func (h *TmuxHandler) GetRecording(w http.ResponseWriter, r *http.Request) {
+	path, errMsg := recordingPath(r)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	if !core.FileExists(path) {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Recording not found: "+r.PathValue("file"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	if r.URL.Query().Get("download") == "1" {
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+r.PathValue("session")+"-"+r.PathValue("file")+"\"")
+	}
+	http.ServeFile(w, r, path)
+}
+
+// DeleteRecording handles DELETE /api/tmux/recordings/{session}/{file}
+func (h *TmuxHandler) DeleteRecording(w http.ResponseWriter, r *http.Request) {
+	path, errMsg := recordingPath(r)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	session, file := r.PathValue("session"), r.PathValue("file")
+
+	if _, active := h.recordingOf(session); active == file {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Recording "+file+" is still running. Stop it first.")
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Recording not found: "+file)
+			return
+		}
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"deleted":   file,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTmuxHandler_Recording_Validation(t *testing.T) {
+	t.Setenv("CHROTE_RECORDINGS_DIR", t.TempDir())
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"start invalid name", http.MethodPost, "/api/tmux/sessions/bad@name/recording", http.StatusBadRequest},
+		{"stop invalid name", http.MethodDelete, "/api/tmux/sessions/bad@name/recording", http.StatusBadRequest},
+		{"list invalid session", http.MethodGet, "/api/tmux/recordings?session=bad@name", http.StatusBadRequest},
+		{"list", http.MethodGet, "/api/tmux/recordings", http.StatusOK},
+		{"get invalid file", http.MethodGet, "/api/tmux/recordings/dev/passwd", http.StatusBadRequest},
+		{"get missing file", http.MethodGet, "/api/tmux/recordings/dev/20260101-000000.cast", http.StatusNotFound},
+		{"delete invalid file", http.MethodDelete, "/api/tmux/recordings/dev/x.cast", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestPipeCommand(t *testing.T) {
+	t.Setenv("CHROTE_LOG_DIR", "/logs")
+	t.Setenv("CHROTE_RECORDINGS_DIR", "/casts")
+
+	st := pipeState{
+		logPane:  "%1",
+		recPane:  "%1",
+		recFile:  "20260101-000000.cast",
+		exe:      "/usr/bin/chrote",
+		geometry: "80 24",
+	}
+	both := pipeCommand("dev", "%1", st)
+	if both != "tee -a '/logs/dev/current.log' | '/usr/bin/chrote' record-cast '/casts/dev/20260101-000000.cast' 80 24 'dev'" {
+		t.Errorf("Unexpected combined command: %s", both)
+	}
+
+	st.recPane = "%2"
+	if got := pipeCommand("dev", "%1", st); got != "cat >> '/logs/dev/current.log'" {
+		t.Errorf("Unexpected log command: %s", got)
+	}
+	if got := pipeCommand("dev", "%2", st); !strings.HasPrefix(got, "exec '/usr/bin/chrote' record-cast") {
+		t.Errorf("Unexpected record command: %s", got)
+	}
+	if got := pipeCommand("dev", "%3", st); got != "" {
+		t.Errorf("Expected no command for an unpiped pane, got %s", got)
+	}
+}
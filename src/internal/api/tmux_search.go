@@ -0,0 +1,197 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+const (
+	// defaultSearchScrollback is how much history is searched per pane unless asked otherwise
+	defaultSearchScrollback = 2000
+	// defaultSearchContext and maxSearchContext bound the lines returned around each match
+	defaultSearchContext = 2
+	maxSearchContext     = 20
+	// defaultSearchLimit and maxSearchLimit bound the matches returned by a search
+	defaultSearchLimit = 200
+	maxSearchLimit     = 2000
+	// searchConcurrency bounds the number of panes captured at once
+	searchConcurrency = 8
+)
+
+// searchPaneFormat lists every pane with where it lives
+const searchPaneFormat = "#{session_name}\t#{window_index}\t#{pane_index}\t#{pane_id}"
+
+// BufferMatch is one search hit in a live pane
+type BufferMatch struct {
+	Session string `json:"session"`
+	Window  int    `json:"window"`
+	Pane    int    `json:"pane"`
+	PaneID  string `json:"paneId"`
+	core.LineMatch
+}
+
+// searchPane identifies one pane to search
+type searchPane struct {
+	session string
+	window  int
+	pane    int
+	id      string
+}
+
+// searchPattern builds the pattern for a search from its query parameters
+// q is required; regex=1 treats it as a regular expression and icase=1 ignores case
+func searchPattern(query url.Values) (*regexp.Regexp, string) {
+	q := query.Get("q")
+	if q == "" {
+		return nil, "Missing required parameter: q"
+	}
+	if query.Get("regex") != "1" {
+		q = regexp.QuoteMeta(q)
+	}
+	if query.Get("icase") == "1" {
+		q = "(?i)" + q
+	}
+	pattern, err := regexp.Compile(q)
+	if err != nil {
+		return nil, "Invalid regex: " + err.Error()
+	}
+	return pattern, ""
+}
+
+// intParam parses an optional integer query parameter within [lo, hi]
+func intParam(query url.Values, name string, def, lo, hi int) (int, string) {
+	s := query.Get(name)
+	if s == "" {
+		return def, ""
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < lo || n > hi {
+		return 0, "Invalid " + name + ". Use a number between " + strconv.Itoa(lo) + " and " + strconv.Itoa(hi) + "."
+	}
+	return n, ""
+}
+
+// parseSearchPanes parses list-panes -a output in searchPaneFormat
+func parseSearchPanes(output string) []searchPane {
+	var panes []searchPane
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(parts) != 4 {
+			continue
+		}
+		window, err1 := strconv.Atoi(parts[1])
+		pane, err2 := strconv.Atoi(parts[2])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		panes = append(panes, searchPane{session: parts[0], window: window, pane: pane, id: parts[3]})
+	}
+	return panes
+}
+
+// SearchSessions handles GET /api/tmux/search
+// Query: q (required), regex, icase, session (limit to one session), scrollback (lines per pane),
+// context (lines around each match), limit (total matches)
+func (h *TmuxHandler) SearchSessions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	pattern, errMsg := searchPattern(query)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	scrollback, errMsg := intParam(query, "scrollback", defaultSearchScrollback, 0, maxCaptureScrollback)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	context, errMsg := intParam(query, "context", defaultSearchContext, 0, maxSearchContext)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	limit, errMsg := intParam(query, "limit", defaultSearchLimit, 1, maxSearchLimit)
+	if errMsg != "" {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	session := query.Get("session")
+	if session != "" {
+		if valid, errMsg := core.ValidateSessionName(session, "session"); !valid {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+			return
+		}
+	}
+
+	args := []string{"list-panes", "-a", "-F", searchPaneFormat}
+	if session != "" {
+		args = []string{"list-panes", "-s", "-t", "=" + session + ":", "-F", searchPaneFormat}
+	}
+	output, err := h.runTmux(args...)
+	if err != nil {
+		if session != "" {
+			core.WriteError(w, tmuxErrorStatus(err), "TMUX_ERROR", err.Error())
+			return
+		}
+		// No server running means nothing to search
+		output = ""
+	}
+	panes := parseSearchPanes(output)
+
+	// Capture in parallel; each pane keeps its own matches so results stay in tmux order
+	results := make([][]core.LineMatch, len(panes))
+	more := make([]bool, len(panes))
+	sem := make(chan struct{}, searchConcurrency)
+	var wg sync.WaitGroup
+	for i, pane := range panes {
+		wg.Add(1)
+		go func(i int, pane searchPane) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// The pane may have closed since it was listed
+			text, err := h.capturePane(pane.id, scrollback, false, true)
+			if err != nil {
+				return
+			}
+			results[i], more[i] = core.FindLineMatches(text, pattern, context, limit)
+		}(i, pane)
+	}
+	wg.Wait()
+
+	matches := []BufferMatch{}
+	truncated := false
+	for i, pane := range panes {
+		for _, m := range results[i] {
+			if len(matches) >= limit {
+				truncated = true
+				break
+			}
+			matches = append(matches, BufferMatch{
+				Session:   pane.session,
+				Window:    pane.window,
+				Pane:      pane.pane,
+				PaneID:    pane.id,
+				LineMatch: m,
+			})
+		}
+		truncated = truncated || more[i]
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"query":     query.Get("q"),
+		"panes":     len(panes),
+		"matches":   matches,
+		"truncated": truncated,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
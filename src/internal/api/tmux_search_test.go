@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTmuxHandler_SearchSessions_Validation(t *testing.T) {
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing query", ""},
+		{"invalid regex", "?q=(&regex=1"},
+		{"invalid scrollback", "?q=x&scrollback=-1"},
+		{"invalid context", "?q=x&context=100"},
+		{"invalid limit", "?q=x&limit=abc"},
+		{"invalid session", "?q=x&session=bad@name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/tmux/search"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d: %s", rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseSearchPanes(t *testing.T) {
+	output := "dev\t0\t0\t%1\ndev\t1\t2\t%5\nbad line\n"
+	panes := parseSearchPanes(output)
+	if len(panes) != 2 {
+		t.Fatalf("Expected 2 panes, got %d", len(panes))
+	}
+	if panes[1].session != "dev" || panes[1].window != 1 || panes[1].pane != 2 || panes[1].id != "%5" {
+		t.Errorf("Unexpected pane: %+v", panes[1])
+	}
+}
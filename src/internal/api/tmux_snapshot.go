@@ -0,0 +1,389 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// restoreTypingTimeout bounds how long restore waits for restored shells before typing into them
+const restoreTypingTimeout = 15 * time.Second
+
+// snapshotPaneFormat prefixes PaneFormat with the session fields a snapshot needs
+const snapshotPaneFormat = "#{session_name}\t#{" + core.GroupOption + "}\t#{" + core.ProtectedOption + "}\t" + core.PaneFormat
+
+// SnapshotRequest is the request body for taking a snapshot
+type SnapshotRequest struct {
+	Name       string   `json:"name,omitempty"`       // Defaults to snapshot-<UTC time>
+	Sessions   []string `json:"sessions,omitempty"`   // Defaults to every session
+	Scrollback int      `json:"scrollback,omitempty"` // Lines of history to save per pane, 0 for none
+}
+
+// RestoreRequest is the request body for restoring a snapshot
+type RestoreRequest struct {
+	Name         string   `json:"name,omitempty"`     // Defaults to the most recent snapshot
+	Sessions     []string `json:"sessions,omitempty"` // Defaults to every session in the snapshot
+	SkipCommands bool     `json:"skipCommands,omitempty"`
+	Scrollback   bool     `json:"scrollback,omitempty"` // Print saved scrollback into the restored panes
+}
+
+// paneInput is text to type into a restored pane once its shell is ready
+type paneInput struct {
+	paneID string
+	lines  []string
+}
+
+// ListSnapshots handles GET /api/tmux/snapshots
+func (h *TmuxHandler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := core.ListSnapshots()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"snapshots": snapshots,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// DeleteSnapshot handles DELETE /api/tmux/snapshots/{snapshot}
+func (h *TmuxHandler) DeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("snapshot")
+	if valid, errMsg := core.ValidateSnapshotName(name); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	err := os.Remove(filepath.Join(core.GetConfigDir(), core.SnapshotFile(name)))
+	if os.IsNotExist(err) {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Snapshot not found: "+name)
+		return
+	}
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"deleted":   name,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// TakeSnapshot handles POST /api/tmux/snapshot
+// Records every session's windows, pane layouts, working directories and foreground commands
+func (h *TmuxHandler) TakeSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req SnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength > 0 {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	now := time.Now().UTC()
+	if req.Name == "" {
+		req.Name = "snapshot-" + now.Format("20060102-150405")
+	}
+	if valid, errMsg := core.ValidateSnapshotName(req.Name); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	if req.Scrollback < 0 || req.Scrollback > maxCaptureScrollback {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST",
+			"Invalid scrollback. Use a number between 0 and "+strconv.Itoa(maxCaptureScrollback)+".")
+		return
+	}
+
+	output, err := h.runTmux("list-panes", "-a", "-F", snapshotPaneFormat)
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "TMUX_ERROR", err.Error())
+		return
+	}
+
+	wanted := make(map[string]bool, len(req.Sessions))
+	for _, name := range req.Sessions {
+		wanted[name] = true
+	}
+
+	snapshot := core.Snapshot{Name: req.Name, Created: now.Format(time.RFC3339), Sessions: []core.SessionSnapshot{}}
+	panes := 0
+	for _, session := range core.ParseSnapshotPanes(output) {
+		if len(wanted) > 0 && !wanted[session.Name] {
+			continue
+		}
+		session.CommandLines = make(map[string]string)
+		if req.Scrollback > 0 {
+			session.Scrollback = make(map[string]string)
+		}
+		for _, window := range session.Windows {
+			for _, pane := range window.Panes {
+				panes++
+				if !pane.Dead && !core.ShellCommands[pane.Command] {
+					session.CommandLines[pane.ID] = foregroundCommandLine(pane.PID, pane.Command)
+				}
+				if req.Scrollback > 0 {
+					if text, err := h.capturePane(pane.ID, req.Scrollback, false, true); err == nil {
+						session.Scrollback[pane.ID] = strings.TrimRight(text, "\n") + "\n"
+					}
+				}
+			}
+		}
+		snapshot.Sessions = append(snapshot.Sessions, session)
+	}
+
+	if err := core.SaveConfigFile(core.SnapshotFile(req.Name), snapshot); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"snapshot":  req.Name,
+		"sessions":  len(snapshot.Sessions),
+		"panes":     panes,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// foregroundCommandLine returns the full command line running under a pane's shell
+// Falls back to the bare command name when it can't be determined
+func foregroundCommandLine(panePID int, command string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "ps", "-o", "args=", "--ppid", strconv.Itoa(panePID)).Output()
+	if err != nil {
+		return command
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+		return line
+	}
+	return command
+}
+
+// RestoreSnapshot handles POST /api/tmux/restore
+// Recreates the snapshot's sessions; sessions that already exist are skipped
+func (h *TmuxHandler) RestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	var req RestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength > 0 {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	if req.Name == "" {
+		snapshots, err := core.ListSnapshots()
+		if err != nil {
+			core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+			return
+		}
+		if len(snapshots) == 0 {
+			core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "No snapshots to restore")
+			return
+		}
+		req.Name = snapshots[0].Name
+	}
+	if valid, errMsg := core.ValidateSnapshotName(req.Name); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	var snapshot core.Snapshot
+	if err := core.LoadConfigFile(core.SnapshotFile(req.Name), &snapshot); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	if snapshot.Name == "" {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Snapshot not found: "+req.Name)
+		return
+	}
+
+	wanted := make(map[string]bool, len(req.Sessions))
+	for _, name := range req.Sessions {
+		wanted[name] = true
+	}
+
+	results := []BulkResult{}
+	var inputs []paneInput
+	failed := 0
+	for _, session := range snapshot.Sessions {
+		if len(wanted) > 0 && !wanted[session.Name] {
+			continue
+		}
+		result := BulkResult{Session: session.Name, Status: BulkStatusOK}
+		if _, err := h.runTmux("has-session", "-t", "="+session.Name); err == nil {
+			result.Status = BulkStatusSkipped
+			result.Reason = "session exists"
+		} else if sessionInputs, err := h.restoreSession(session, req); err != nil {
+			result.Status = BulkStatusError
+			result.Reason = err.Error()
+			failed++
+		} else {
+			inputs = append(inputs, sessionInputs...)
+		}
+		results = append(results, result)
+	}
+
+	h.typeIntoPanes(inputs, restoreTypingTimeout)
+	h.invalidateCache()
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   failed == 0,
+		"snapshot":  snapshot.Name,
+		"results":   results,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// restoreSession rebuilds one session's windows and panes
+// Returns the commands and scrollback to type into the new panes; on failure the
+// half-built session is killed
+func (h *TmuxHandler) restoreSession(session core.SessionSnapshot, req RestoreRequest) ([]paneInput, error) {
+	inputs, err := h.buildRestoredSession(session, req)
+	if err != nil {
+		h.runTmux("kill-session", "-t", "="+session.Name)
+		return nil, err
+	}
+	return inputs, nil
+}
+
+// buildRestoredSession creates the session, its windows and panes, and restores options
+func (h *TmuxHandler) buildRestoredSession(session core.SessionSnapshot, req RestoreRequest) ([]paneInput, error) {
+	var inputs []paneInput
+	activeWindow := ""
+	created := false
+
+	for _, window := range session.Windows {
+		if len(window.Panes) == 0 {
+			continue
+		}
+		windowTarget := "=" + session.Name + ":" + strconv.Itoa(window.Index)
+		first := window.Panes[0]
+
+		var args []string
+		if !created {
+			args = []string{"new-session", "-d", "-s", session.Name, "-c", restoreDir(first.Path), "-P", "-F", "#{window_index}\t#{pane_id}"}
+		} else {
+			args = []string{"new-window", "-d", "-t", windowTarget, "-c", restoreDir(first.Path), "-P", "-F", "#{window_index}\t#{pane_id}"}
+		}
+		if window.Name != "" {
+			args = append(args, "-n", window.Name)
+		}
+		output, err := h.runTmux(args...)
+		if err != nil {
+			return nil, err
+		}
+		created = true
+		index, firstPane, _ := strings.Cut(strings.TrimSpace(output), "\t")
+		if index != strconv.Itoa(window.Index) {
+			// base-index may differ from when the snapshot was taken
+			if _, err := h.runTmux("move-window", "-s", "="+session.Name+":"+index, "-t", windowTarget); err != nil {
+				return nil, err
+			}
+		}
+
+		paneIDs := []string{firstPane}
+		for _, pane := range window.Panes[1:] {
+			output, err := h.runTmux("split-window", "-d", "-t", firstPane, "-c", restoreDir(pane.Path), "-P", "-F", "#{pane_id}")
+			if err != nil {
+				return nil, err
+			}
+			paneIDs = append(paneIDs, strings.TrimSpace(output))
+			// Re-tile after every split so later splits have room
+			h.runTmux("select-layout", "-t", firstPane, "tiled")
+		}
+		if window.Layout != "" {
+			// Exact layouts only apply when the pane count matches, which it does here
+			h.runTmux("select-layout", "-t", firstPane, window.Layout)
+		}
+
+		for j, pane := range window.Panes {
+			if pane.Active {
+				h.runTmux("select-pane", "-t", paneIDs[j])
+			}
+			var lines []string
+			if req.Scrollback && session.Scrollback[pane.ID] != "" {
+				if file, err := writeScrollbackFile(session.Scrollback[pane.ID]); err == nil {
+					lines = append(lines, "cat -- '"+file+"' && rm -f -- '"+file+"'")
+				}
+			}
+			if !req.SkipCommands && session.CommandLines[pane.ID] != "" {
+				lines = append(lines, session.CommandLines[pane.ID])
+			}
+			if len(lines) > 0 {
+				inputs = append(inputs, paneInput{paneID: paneIDs[j], lines: lines})
+			}
+		}
+		if window.Active {
+			activeWindow = windowTarget
+		}
+	}
+
+	if activeWindow != "" {
+		h.runTmux("select-window", "-t", activeWindow)
+	}
+	if session.Group != "" {
+		if _, err := h.runTmux("set-option", "-t", "="+session.Name+":", core.GroupOption, session.Group); err != nil {
+			return nil, err
+		}
+	}
+	if session.Protected {
+		if _, err := h.runTmux("set-option", "-t", "="+session.Name+":", core.ProtectedOption, "1"); err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// restoreDir returns dir if it still exists, otherwise the default working directory
+func restoreDir(dir string) string {
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir
+	}
+	return core.GetWorkDir()
+}
+
+// writeScrollbackFile writes saved scrollback to a temp file for a restored pane to print
+func writeScrollbackFile(text string) (string, error) {
+	f, err := os.CreateTemp("", "chrote-scrollback-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// typeIntoPanes types each pane's lines once its shell is ready, all panes in parallel
+func (h *TmuxHandler) typeIntoPanes(inputs []paneInput, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, input := range inputs {
+		wg.Add(1)
+		go func(input paneInput) {
+			defer wg.Done()
+			// Shells discard typeahead while starting up, so wait for the prompt
+			h.waitForPrompt(ctx, input.paneID, core.PromptRegex)
+			for _, line := range input.lines {
+				h.sendInput(input.paneID, SendInputRequest{Text: line, Enter: true})
+			}
+		}(input)
+	}
+	wg.Wait()
+}
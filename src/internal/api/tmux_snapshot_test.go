@@ -0,0 +1,44 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTmuxHandler_Snapshot_Validation(t *testing.T) {
+	t.Setenv("CHROTE_CONFIG_DIR", t.TempDir())
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"snapshot invalid JSON", http.MethodPost, "/api/tmux/snapshot", `{bad`, http.StatusBadRequest},
+		{"snapshot invalid name", http.MethodPost, "/api/tmux/snapshot", `{"name":"../etc"}`, http.StatusBadRequest},
+		{"snapshot bad scrollback", http.MethodPost, "/api/tmux/snapshot", `{"scrollback":-1}`, http.StatusBadRequest},
+		{"restore without snapshots", http.MethodPost, "/api/tmux/restore", `{}`, http.StatusNotFound},
+		{"restore unknown snapshot", http.MethodPost, "/api/tmux/restore", `{"name":"nope"}`, http.StatusNotFound},
+		{"restore invalid name", http.MethodPost, "/api/tmux/restore", `{"name":"a/b"}`, http.StatusBadRequest},
+		{"delete unknown snapshot", http.MethodDelete, "/api/tmux/snapshots/nope", "", http.StatusNotFound},
+		{"list", http.MethodGet, "/api/tmux/snapshots", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
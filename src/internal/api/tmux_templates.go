@@ -0,0 +1,328 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+// templateShellTimeout bounds how long a new pane's shell gets to draw its prompt before commands are typed
+// Shells whose prompt doesn't match core.PromptRegex get their commands once it expires
+const templateShellTimeout = 5 * time.Second
+
+// loadTemplates reads the stored session templates
+func (h *TmuxHandler) loadTemplates() ([]core.SessionTemplate, error) {
+	templates := []core.SessionTemplate{}
+	if err := core.LoadConfigFile(core.TemplatesFile, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// ListTemplates handles GET /api/tmux/templates
+func (h *TmuxHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	h.templatesMu.Lock()
+	templates, err := h.loadTemplates()
+	h.templatesMu.Unlock()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"templates": templates,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// GetTemplate handles GET /api/tmux/templates/{template}
+// Also reports the variables the template expects
+func (h *TmuxHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("template")
+
+	h.templatesMu.Lock()
+	templates, err := h.loadTemplates()
+	h.templatesMu.Unlock()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	i := core.FindTemplate(templates, name)
+	if i < 0 {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Template not found: "+name)
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"template":  templates[i],
+		"vars":      core.TemplateVars(templates[i]),
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// PutTemplate handles PUT /api/tmux/templates/{template}
+// Creates the template or replaces an existing one with the same name
+func (h *TmuxHandler) PutTemplate(w http.ResponseWriter, r *http.Request) {
+	var template core.SessionTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	name := r.PathValue("template")
+	if template.Name == "" {
+		template.Name = name
+	}
+	if template.Name != name {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Template name in body does not match the URL")
+		return
+	}
+	if valid, errMsg := core.ValidateTemplate(template); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+
+	h.templatesMu.Lock()
+	defer h.templatesMu.Unlock()
+
+	templates, err := h.loadTemplates()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	created := false
+	if i := core.FindTemplate(templates, name); i >= 0 {
+		templates[i] = template
+	} else {
+		templates = append(templates, template)
+		created = true
+	}
+
+	if err := core.SaveConfigFile(core.TemplatesFile, templates); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"template":  template,
+		"created":   created,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// DeleteTemplate handles DELETE /api/tmux/templates/{template}
+func (h *TmuxHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("template")
+
+	h.templatesMu.Lock()
+	defer h.templatesMu.Unlock()
+
+	templates, err := h.loadTemplates()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	i := core.FindTemplate(templates, name)
+	if i < 0 {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Template not found: "+name)
+		return
+	}
+	templates = append(templates[:i], templates[i+1:]...)
+
+	if err := core.SaveConfigFile(core.TemplatesFile, templates); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"deleted":   name,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// resolveTemplate looks up a template and fills in its variables for a new session
+// Returns an error code and message on failure
+func (h *TmuxHandler) resolveTemplate(name, session string, vars map[string]string) (core.SessionTemplate, string, string) {
+	h.templatesMu.Lock()
+	templates, err := h.loadTemplates()
+	h.templatesMu.Unlock()
+	if err != nil {
+		return core.SessionTemplate{}, "INTERNAL_ERROR", err.Error()
+	}
+
+	i := core.FindTemplate(templates, name)
+	if i < 0 {
+		return core.SessionTemplate{}, "NOT_FOUND", "Template not found: " + name
+	}
+
+	values := map[string]string{"session": session}
+	for key, value := range vars {
+		if key != "session" {
+			values[key] = value
+		}
+	}
+	resolved, missing := core.ApplyTemplateVars(templates[i], values)
+	if len(missing) > 0 {
+		return core.SessionTemplate{}, "BAD_REQUEST", "Missing template variables: " + strings.Join(missing, ", ")
+	}
+
+	code, msg := validateResolvedTemplate(&resolved)
+	return resolved, code, msg
+}
+
+// validateResolvedTemplate checks the values that may have come from variables,
+// resolving paths against the allowed roots and filling in inherited working directories
+func validateResolvedTemplate(t *core.SessionTemplate) (string, string) {
+	if t.Group != "" {
+		if valid, errMsg := core.ValidateSessionName(t.Group, "group"); !valid {
+			return "BAD_REQUEST", errMsg
+		}
+	}
+
+	checkCwd := func(cwd, inherited string) (string, string, string) {
+		if cwd == "" {
+			return inherited, "", ""
+		}
+		return core.ValidateProjectPath(cwd)
+	}
+	checkCommands := func(commands []string) (string, string) {
+		for _, command := range commands {
+			if valid, errMsg := core.ValidateInputText(command, "command"); !valid {
+				return "BAD_REQUEST", errMsg
+			}
+		}
+		return "", ""
+	}
+
+	cwd, code, msg := checkCwd(t.Cwd, core.GetWorkDir())
+	if code != "" {
+		return code, msg
+	}
+	t.Cwd = cwd
+
+	if len(t.Windows) == 0 {
+		t.Windows = []core.TemplateWindow{{}}
+	}
+	for i := range t.Windows {
+		window := &t.Windows[i]
+		if window.Name != "" {
+			if valid, errMsg := core.ValidateWindowName(window.Name); !valid {
+				return "BAD_REQUEST", errMsg
+			}
+		}
+		if window.Cwd, code, msg = checkCwd(window.Cwd, t.Cwd); code != "" {
+			return code, msg
+		}
+		if code, msg = checkCommands(window.Commands); code != "" {
+			return code, msg
+		}
+		for j := range window.Panes {
+			pane := &window.Panes[j]
+			if pane.Cwd, code, msg = checkCwd(pane.Cwd, window.Cwd); code != "" {
+				return code, msg
+			}
+			if code, msg = checkCommands(pane.Commands); code != "" {
+				return code, msg
+			}
+		}
+	}
+	return "", ""
+}
+
+// launchTemplate creates a session from a resolved template
+// If any step fails the half-built session is killed
+func (h *TmuxHandler) launchTemplate(session string, t core.SessionTemplate) error {
+	for i, window := range t.Windows {
+		var args []string
+		if i == 0 {
+			args = []string{"new-session", "-d", "-s", session, "-c", window.Cwd, "-P", "-F", "#{pane_id}"}
+			for key, value := range t.Env {
+				args = append(args, "-e", key+"="+value)
+			}
+		} else {
+			args = []string{"new-window", "-d", "-t", "=" + session + ":", "-c", window.Cwd, "-P", "-F", "#{pane_id}"}
+		}
+		if window.Name != "" {
+			args = append(args, "-n", window.Name)
+		}
+
+		output, err := h.runTmux(args...)
+		if err != nil {
+			if i > 0 {
+				h.runTmux("kill-session", "-t", "="+session)
+			}
+			return err
+		}
+
+		if err := h.buildTemplateWindow(strings.TrimSpace(output), window); err != nil {
+			h.runTmux("kill-session", "-t", "="+session)
+			return fmt.Errorf("window %d: %w", i, err)
+		}
+
+		if i == 0 && t.Group != "" {
+			if _, err := h.runTmux("set-option", "-t", "="+session+":", core.GroupOption, t.Group); err != nil {
+				h.runTmux("kill-session", "-t", "="+session)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buildTemplateWindow splits a new window's panes, applies its layout and types its commands
+func (h *TmuxHandler) buildTemplateWindow(firstPane string, window core.TemplateWindow) error {
+	layout := window.Layout
+	if layout == "" {
+		layout = "tiled"
+	}
+
+	paneIDs := []string{firstPane}
+	for _, pane := range window.Panes {
+		output, err := h.runTmux("split-window", "-d", "-t", firstPane, "-c", pane.Cwd, "-P", "-F", "#{pane_id}")
+		if err != nil {
+			return err
+		}
+		paneIDs = append(paneIDs, strings.TrimSpace(output))
+		// Re-tile after every split so later splits have room
+		if _, err := h.runTmux("select-layout", "-t", firstPane, layout); err != nil {
+			return err
+		}
+	}
+	if window.Layout != "" && len(window.Panes) == 0 {
+		if _, err := h.runTmux("select-layout", "-t", firstPane, layout); err != nil {
+			return err
+		}
+	}
+
+	commands := [][]string{window.Commands}
+	for _, pane := range window.Panes {
+		commands = append(commands, pane.Commands)
+	}
+	for i, paneID := range paneIDs {
+		if len(commands[i]) == 0 {
+			continue
+		}
+		// Shells discard typeahead while starting up, so wait for the prompt
+		ctx, cancel := context.WithTimeout(context.Background(), templateShellTimeout)
+		h.waitForPrompt(ctx, paneID, core.PromptRegex)
+		cancel()
+
+		for _, command := range commands[i] {
+			if err := h.sendInput(paneID, SendInputRequest{Text: command, Enter: true}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
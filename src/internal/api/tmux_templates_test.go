@@ -0,0 +1,109 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTmuxHandler_Templates_CRUD(t *testing.T) {
+	t.Setenv("CHROTE_CONFIG_DIR", t.TempDir())
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := do(http.MethodPut, "/api/tmux/templates/agent", `{"cwd":"/code/{{project}}","windows":[{"commands":["claude"]}]}`); rr.Code != http.StatusOK {
+		t.Fatalf("PUT returned %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr := do(http.MethodGet, "/api/tmux/templates/agent", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET returned %d: %s", rr.Code, rr.Body.String())
+	}
+	var got struct {
+		Vars []string `json:"vars"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &got)
+	if len(got.Vars) != 1 || got.Vars[0] != "project" {
+		t.Errorf("Expected vars [project], got %v", got.Vars)
+	}
+
+	rr = do(http.MethodGet, "/api/tmux/templates", "")
+	var list struct {
+		Templates []map[string]interface{} `json:"templates"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Templates) != 1 || list.Templates[0]["name"] != "agent" {
+		t.Errorf("Unexpected template list: %s", rr.Body.String())
+	}
+
+	if rr := do(http.MethodDelete, "/api/tmux/templates/agent", ""); rr.Code != http.StatusOK {
+		t.Errorf("DELETE returned %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodGet, "/api/tmux/templates/agent", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("GET after delete returned %d, expected 404", rr.Code)
+	}
+}
+
+func TestTmuxHandler_Templates_Validation(t *testing.T) {
+	t.Setenv("CHROTE_CONFIG_DIR", t.TempDir())
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"invalid JSON", http.MethodPut, "/api/tmux/templates/agent", `{bad`, http.StatusBadRequest},
+		{"name mismatch", http.MethodPut, "/api/tmux/templates/agent", `{"name":"other"}`, http.StatusBadRequest},
+		{"invalid name", http.MethodPut, "/api/tmux/templates/bad@name", `{}`, http.StatusBadRequest},
+		{"invalid layout", http.MethodPut, "/api/tmux/templates/agent", `{"windows":[{"layout":"spiral"}]}`, http.StatusBadRequest},
+		{"delete unknown", http.MethodDelete, "/api/tmux/templates/nope", "", http.StatusNotFound},
+		{"create from unknown template", http.MethodPost, "/api/tmux/sessions", `{"name":"x","template":"nope"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestTmuxHandler_CreateSession_MissingTemplateVars(t *testing.T) {
+	t.Setenv("CHROTE_CONFIG_DIR", t.TempDir())
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/tmux/templates/agent", bytes.NewBufferString(`{"cwd":"/code/{{project}}"}`))
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/tmux/sessions", bytes.NewBufferString(`{"name":"x","template":"agent"}`))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest || !bytes.Contains(rr.Body.Bytes(), []byte("project")) {
+		t.Errorf("Expected 400 naming the missing variable, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
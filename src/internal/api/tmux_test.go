@@ -199,3 +199,16 @@ func TestTmuxHandler_ListSessions_ReturnsValidJSON(t *testing.T) {
 		t.Error("Grouped should be initialized map, not nil")
 	}
 }
+
+func TestTmuxHandler_ListSessions_InvalidSort(t *testing.T) {
+	handler := NewTmuxHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tmux/sessions?sort=random", nil)
+	recorder := httptest.NewRecorder()
+
+	handler.ListSessions(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Status code = %d, expected %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,583 @@
+// Package api provides HTTP handlers for the API
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+)
+
+const (
+	// watchInterval is how often the watcher engine tails the panes of watched sessions
+	watchInterval = 2 * time.Second
+	// watchHistoryLines is how much history above the screen is captured per sample;
+	// output that scrolls further than this between samples is not seen
+	watchHistoryLines = 100
+	// watchActionTimeout bounds the external commands run by nudge and bead actions
+	watchActionTimeout = 30 * time.Second
+	// bdCommand is the beads CLI used by bead actions
+	bdCommand = "bd"
+)
+
+// watchPaneFormat lists every pane with its session and screen height
+const watchPaneFormat = "#{session_name}\t#{window_index}\t#{pane_index}\t#{pane_id}\t#{pane_dead}\t#{pane_height}"
+
+// WatcherStatus reports what a watcher has done since the server started
+type WatcherStatus struct {
+	Fires     int    `json:"fires"`
+	LastFired string `json:"lastFired,omitempty"`
+	LastMatch string `json:"lastMatch,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// watchedPane is what the engine remembers about a pane between samples
+type watchedPane struct {
+	lines     []string
+	lastFired map[string]time.Time // keyed by watcher name
+}
+
+// watcherEngine tails the output of watched sessions and fires the actions of
+// watchers whose pattern matches new output (see core.NewOutputLines)
+type watcherEngine struct {
+	handler *TmuxHandler
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	running  bool
+	stop     chan struct{}
+	watchers []core.Watcher
+	patterns []*regexp.Regexp
+	panes    map[string]*watchedPane // keyed by pane ID
+	status   map[string]*WatcherStatus
+}
+
+// newWatcherEngine creates a watcher engine for the given handler
+func newWatcherEngine(h *TmuxHandler) *watcherEngine {
+	return &watcherEngine{
+		handler: h,
+		panes:   make(map[string]*watchedPane),
+		status:  make(map[string]*WatcherStatus),
+	}
+}
+
+// loadWatchers reads the stored watchers
+func loadWatchers() ([]core.Watcher, error) {
+	watchers := []core.Watcher{}
+	if err := core.LoadConfigFile(core.WatchersFile, &watchers); err != nil {
+		return nil, err
+	}
+	return watchers, nil
+}
+
+// setWatchers replaces the active watchers, skipping any that fail validation
+func (e *watcherEngine) setWatchers(watchers []core.Watcher) {
+	var active []core.Watcher
+	var patterns []*regexp.Regexp
+	for _, w := range watchers {
+		if valid, errMsg := core.ValidateWatcher(w); !valid {
+			log.Printf("Ignoring invalid watcher %q: %s", w.Name, errMsg)
+			continue
+		}
+		active = append(active, w)
+		patterns = append(patterns, regexp.MustCompile(w.Pattern))
+	}
+
+	e.mu.Lock()
+	e.watchers = active
+	e.patterns = patterns
+	for name := range e.status {
+		if core.FindWatcher(active, name) < 0 {
+			delete(e.status, name)
+		}
+	}
+	e.mu.Unlock()
+}
+
+// statusOf returns a copy of a watcher's status
+func (e *watcherEngine) statusOf(name string) WatcherStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if s, ok := e.status[name]; ok {
+		return *s
+	}
+	return WatcherStatus{}
+}
+
+// Start loads the stored watchers and starts tailing in the background
+func (e *watcherEngine) Start() {
+	watchers, err := loadWatchers()
+	if err != nil {
+		log.Printf("Failed to load watchers: %v", err)
+	}
+	e.setWatchers(watchers)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.running {
+		return
+	}
+	e.running = true
+	e.stop = make(chan struct{})
+
+	e.wg.Add(1)
+	go e.run(e.stop)
+}
+
+// Stop stops tailing and waits for running actions to finish
+func (e *watcherEngine) Stop() {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return
+	}
+	e.running = false
+	close(e.stop)
+	e.mu.Unlock()
+
+	e.wg.Wait()
+
+	e.mu.Lock()
+	e.panes = make(map[string]*watchedPane)
+	e.mu.Unlock()
+}
+
+// run samples on a fixed interval until stop closes
+func (e *watcherEngine) run(stop chan struct{}) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		e.sample()
+	}
+}
+
+// watchTarget is a pane of a watched session together with the watchers watching it
+type watchTarget struct {
+	session core.Session
+	window  string
+	pane    string
+	id      string
+	height  int
+	indexes []int // into watchers and patterns
+}
+
+// sample captures the panes of every watched session and fires matching watchers
+func (e *watcherEngine) sample() {
+	e.mu.Lock()
+	watchers, patterns := e.watchers, e.patterns
+	e.mu.Unlock()
+
+	if len(watchers) == 0 {
+		return
+	}
+
+	all := e.handler.sessions().Sessions
+	sessions := make(map[string]core.Session)
+	watchedBy := make(map[string][]int)
+	now := time.Now()
+	for i, w := range watchers {
+		if w.Disabled {
+			continue
+		}
+		for _, session := range core.SelectSessions(all, w.Selector, now) {
+			sessions[session.Name] = session
+			watchedBy[session.Name] = append(watchedBy[session.Name], i)
+		}
+	}
+	if len(sessions) == 0 {
+		e.mu.Lock()
+		e.panes = make(map[string]*watchedPane)
+		e.mu.Unlock()
+		return
+	}
+
+	output, err := e.handler.runTmux("list-panes", "-a", "-F", watchPaneFormat)
+	if err != nil {
+		return
+	}
+	var targets []watchTarget
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(parts) != 6 || parts[4] == "1" {
+			continue
+		}
+		session, ok := sessions[parts[0]]
+		if !ok {
+			continue
+		}
+		height, _ := strconv.Atoi(parts[5])
+		targets = append(targets, watchTarget{
+			session: session,
+			window:  parts[1],
+			pane:    parts[2],
+			id:      parts[3],
+			height:  height,
+			indexes: watchedBy[parts[0]],
+		})
+	}
+
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		seen[t.id] = true
+		text, err := e.handler.runTmux("capture-pane", "-p", "-J", "-t", t.id, "-S", "-"+strconv.Itoa(watchHistoryLines))
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+
+		e.mu.Lock()
+		state, known := e.panes[t.id]
+		if !known {
+			state = &watchedPane{lastFired: make(map[string]time.Time)}
+			e.panes[t.id] = state
+		}
+		prev := state.lines
+		state.lines = lines
+		e.mu.Unlock()
+
+		// A newly watched pane only has its visible screen checked, not old history
+		fresh := lines
+		if known {
+			fresh = core.NewOutputLines(prev, lines)
+		} else if t.height > 0 && len(fresh) > t.height {
+			fresh = fresh[len(fresh)-t.height:]
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+
+		for _, i := range t.indexes {
+			match := lastMatch(fresh, patterns[i])
+			if match == "" {
+				continue
+			}
+			w := watchers[i]
+
+			e.mu.Lock()
+			cooling := now.Sub(state.lastFired[w.Name]) < core.WatchCooldown(w)
+			if !cooling {
+				state.lastFired[w.Name] = now
+			}
+			e.mu.Unlock()
+			if cooling {
+				continue
+			}
+
+			e.wg.Add(1)
+			go func(w core.Watcher, t watchTarget, match string) {
+				defer e.wg.Done()
+				e.fire(w, t, match)
+			}(w, t, match)
+		}
+	}
+
+	e.mu.Lock()
+	for id := range e.panes {
+		if !seen[id] {
+			delete(e.panes, id)
+		}
+	}
+	e.mu.Unlock()
+}
+
+// lastMatch returns the last line matching pattern, or ""
+func lastMatch(lines []string, pattern *regexp.Regexp) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if pattern.MatchString(lines[i]) {
+			return strings.TrimSpace(lines[i])
+		}
+	}
+	return ""
+}
+
+// fire runs a watcher's action for a match and publishes a watcher-fired event
+func (e *watcherEngine) fire(w core.Watcher, t watchTarget, match string) {
+	err := e.handler.runWatchAction(w, t, match)
+
+	e.mu.Lock()
+	status, ok := e.status[w.Name]
+	if !ok {
+		status = &WatcherStatus{}
+		e.status[w.Name] = status
+	}
+	status.Fires++
+	status.LastFired = time.Now().UTC().Format(time.RFC3339)
+	status.LastMatch = match
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	e.mu.Unlock()
+
+	data := map[string]interface{}{
+		"watcher": w.Name,
+		"action":  w.Action,
+		"window":  t.window,
+		"pane":    t.pane,
+		"paneId":  t.id,
+		"match":   match,
+	}
+	if err != nil {
+		log.Printf("Watcher %s failed on %s: %v", w.Name, t.session.Name, err)
+		data["error"] = err.Error()
+	}
+	e.handler.events.Publish(NewEvent(EventWatcherFired, t.session.Name, data))
+}
+
+// runWatchAction performs a watcher's action for a match in one pane
+func (h *TmuxHandler) runWatchAction(w core.Watcher, t watchTarget, match string) error {
+	vars := map[string]string{
+		"session": t.session.Name,
+		"window":  t.window,
+		"pane":    t.pane,
+		"match":   match,
+	}
+
+	switch w.Action {
+	case core.WatchActionSendKeys:
+		return h.sendInput(t.id, SendInputRequest{Text: w.Text, Keys: w.Keys, Enter: w.Enter})
+
+	case core.WatchActionKill:
+		if t.session.Protected {
+			return fmt.Errorf("session %s is protected", t.session.Name)
+		}
+		if _, err := h.runTmux("kill-session", "-t", "="+t.session.Name); err != nil {
+			return err
+		}
+		log.Printf("Watcher %s killed session %s", w.Name, t.session.Name)
+		h.invalidateCache()
+
+	case core.WatchActionNudge:
+		message := "Watcher " + w.Name + " matched in {{session}}: {{match}}"
+		if w.Message != "" {
+			message = w.Message
+		}
+		return runWatchCommand(w.Workspace, "gt", "nudge", w.Target, "-m", core.ExpandWatchVars(message, vars))
+
+	case core.WatchActionBead:
+		title := "{{session}}: {{match}}"
+		if w.Title != "" {
+			title = w.Title
+		}
+		description := fmt.Sprintf("Watcher %s matched output of %s (window %s, pane %s):\n\n%s",
+			w.Name, t.session.Name, t.window, t.pane, match)
+		return runWatchCommand(w.Project, bdCommand, "create", core.ExpandWatchVars(title, vars), "-d", description, "-t", "bug")
+	}
+	return nil
+}
+
+// runWatchCommand runs an external command in dir, bounded by watchActionTimeout
+func runWatchCommand(dir, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), watchActionTimeout)
+	defer cancel()
+
+	cmd := execCommand(name, args...)
+	cmd.Dir = dir
+	done := make(chan error, 1)
+	var output []byte
+	go func() {
+		var err error
+		output, err = cmd.CombinedOutput()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%s %s failed: %v: %s", name, args[0], err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	case <-ctx.Done():
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+		return fmt.Errorf("%s %s timed out after %v", name, args[0], watchActionTimeout)
+	}
+}
+
+// validateWatcherPaths checks the workspace or project a watcher's action runs in
+func validateWatcherPaths(w *core.Watcher) (string, string) {
+	switch w.Action {
+	case core.WatchActionNudge:
+		resolved, code, msg := core.ValidateProjectPath(w.Workspace)
+		if code != "" {
+			return code, msg
+		}
+		if !core.FileExists(filepath.Join(resolved, "daemon")) {
+			return "BAD_REQUEST", "Not a valid Gastown workspace: " + w.Workspace
+		}
+		w.Workspace = resolved
+	case core.WatchActionBead:
+		resolved, code, msg := core.ValidateProjectPath(w.Project)
+		if code != "" {
+			return code, msg
+		}
+		if !core.FileExists(filepath.Join(resolved, ".beads")) {
+			return "BAD_REQUEST", "No .beads directory found in " + w.Project
+		}
+		w.Project = resolved
+	}
+	return "", ""
+}
+
+// watcherInfo pairs a watcher with its status for responses
+type watcherInfo struct {
+	core.Watcher
+	Status WatcherStatus `json:"status"`
+}
+
+// ListWatchers handles GET /api/tmux/watchers
+func (h *TmuxHandler) ListWatchers(w http.ResponseWriter, r *http.Request) {
+	h.watchersMu.Lock()
+	watchers, err := loadWatchers()
+	h.watchersMu.Unlock()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	infos := make([]watcherInfo, 0, len(watchers))
+	for _, watcher := range watchers {
+		infos = append(infos, watcherInfo{Watcher: watcher, Status: h.watchers.statusOf(watcher.Name)})
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"watchers":  infos,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// GetWatcher handles GET /api/tmux/watchers/{watcher}
+func (h *TmuxHandler) GetWatcher(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("watcher")
+
+	h.watchersMu.Lock()
+	watchers, err := loadWatchers()
+	h.watchersMu.Unlock()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	i := core.FindWatcher(watchers, name)
+	if i < 0 {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Watcher not found: "+name)
+		return
+	}
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"watcher":   watcherInfo{Watcher: watchers[i], Status: h.watchers.statusOf(name)},
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// PutWatcher handles PUT /api/tmux/watchers/{watcher}
+// Creates the watcher or replaces an existing one with the same name
+func (h *TmuxHandler) PutWatcher(w http.ResponseWriter, r *http.Request) {
+	var watcher core.Watcher
+	if err := json.NewDecoder(r.Body).Decode(&watcher); err != nil {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid JSON body")
+		return
+	}
+
+	name := r.PathValue("watcher")
+	if watcher.Name == "" {
+		watcher.Name = name
+	}
+	if watcher.Name != name {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "Watcher name in body does not match the URL")
+		return
+	}
+	if valid, errMsg := core.ValidateWatcher(watcher); !valid {
+		core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", errMsg)
+		return
+	}
+	if code, msg := validateWatcherPaths(&watcher); code != "" {
+		core.WriteError(w, core.GetErrorStatusCode(code), code, msg)
+		return
+	}
+
+	h.watchersMu.Lock()
+	defer h.watchersMu.Unlock()
+
+	watchers, err := loadWatchers()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	created := false
+	if i := core.FindWatcher(watchers, name); i >= 0 {
+		watchers[i] = watcher
+	} else {
+		if len(watchers) >= core.MaxWatchers {
+			core.WriteError(w, http.StatusBadRequest, "BAD_REQUEST", "too many watchers (max 100).")
+			return
+		}
+		watchers = append(watchers, watcher)
+		created = true
+	}
+
+	if err := core.SaveConfigFile(core.WatchersFile, watchers); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	h.watchers.setWatchers(watchers)
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"watcher":   watcher,
+		"created":   created,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// DeleteWatcher handles DELETE /api/tmux/watchers/{watcher}
+func (h *TmuxHandler) DeleteWatcher(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("watcher")
+
+	h.watchersMu.Lock()
+	defer h.watchersMu.Unlock()
+
+	watchers, err := loadWatchers()
+	if err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	i := core.FindWatcher(watchers, name)
+	if i < 0 {
+		core.WriteError(w, http.StatusNotFound, "NOT_FOUND", "Watcher not found: "+name)
+		return
+	}
+	watchers = append(watchers[:i], watchers[i+1:]...)
+
+	if err := core.SaveConfigFile(core.WatchersFile, watchers); err != nil {
+		core.WriteError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+	h.watchers.setWatchers(watchers)
+
+	core.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"deleted":   name,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestTmuxHandler_Watchers_CRUD(t *testing.T) {
+	t.Setenv("CHROTE_CONFIG_DIR", t.TempDir())
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	body := `{"selector":{"glob":"gt-*"},"pattern":"Proceed\\? \\(y/n\\)","action":"send-keys","text":"y","enter":true}`
+	if rr := do(http.MethodPut, "/api/tmux/watchers/proceed", body); rr.Code != http.StatusOK {
+		t.Fatalf("PUT returned %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(handler.watchers.watchers) != 1 {
+		t.Errorf("Expected the engine to pick up the new watcher, got %d", len(handler.watchers.watchers))
+	}
+
+	rr := do(http.MethodGet, "/api/tmux/watchers", "")
+	var list struct {
+		Watchers []map[string]interface{} `json:"watchers"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Watchers) != 1 || list.Watchers[0]["name"] != "proceed" || list.Watchers[0]["status"] == nil {
+		t.Errorf("Unexpected watcher list: %s", rr.Body.String())
+	}
+
+	if rr := do(http.MethodGet, "/api/tmux/watchers/proceed", ""); rr.Code != http.StatusOK {
+		t.Errorf("GET returned %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodDelete, "/api/tmux/watchers/proceed", ""); rr.Code != http.StatusOK {
+		t.Errorf("DELETE returned %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodGet, "/api/tmux/watchers/proceed", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("GET after delete returned %d, expected 404", rr.Code)
+	}
+	if len(handler.watchers.watchers) != 0 {
+		t.Errorf("Expected the engine to drop the deleted watcher")
+	}
+}
+
+func TestTmuxHandler_Watchers_Validation(t *testing.T) {
+	t.Setenv("CHROTE_CONFIG_DIR", t.TempDir())
+
+	handler := NewTmuxHandler()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"invalid JSON", http.MethodPut, "/api/tmux/watchers/w", `{bad`, http.StatusBadRequest},
+		{"name mismatch", http.MethodPut, "/api/tmux/watchers/w", `{"name":"other"}`, http.StatusBadRequest},
+		{"invalid pattern", http.MethodPut, "/api/tmux/watchers/w", `{"selector":{"glob":"*"},"pattern":"(","action":"event"}`, http.StatusBadRequest},
+		{"nudge outside allowed roots", http.MethodPut, "/api/tmux/watchers/w",
+			`{"selector":{"glob":"*"},"pattern":"x","action":"nudge","workspace":"/nonexistent-root/gt","target":"rig"}`, http.StatusForbidden},
+		{"delete unknown", http.MethodDelete, "/api/tmux/watchers/nope", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestLastMatch(t *testing.T) {
+	pattern := regexp.MustCompile("error")
+	if got := lastMatch([]string{"error 1", "ok", "  error 2  "}, pattern); got != "error 2" {
+		t.Errorf("Expected last match, got %q", got)
+	}
+	if got := lastMatch([]string{"ok"}, pattern); got != "" {
+		t.Errorf("Expected no match, got %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Agent states reported by the session monitor
+const (
+	StateWorking = "working" // Output is changing
+	StateIdle    = "idle"    // Quiet, sitting at a prompt
+	StateWaiting = "waiting" // Blocked on the user, e.g. a permission or y/n prompt
+	StateCrashed = "crashed" // Pane is dead, or the agent exited back to the shell
+)
+
+// stateTailLines is how many trailing non-empty lines are checked for input prompts
+const stateTailLines = 12
+
+// WaitingPatterns match prompts where an agent is blocked until someone answers
+var WaitingPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)[(\[]y/n[)\]]`),
+	regexp.MustCompile(`(?i)\(yes/no\)`),
+	regexp.MustCompile(`(?i)do you want to (proceed|continue|make this edit|create|run)`),
+	regexp.MustCompile(`(?i)proceed\?\s*$`),
+	regexp.MustCompile(`(?i)press enter to continue`),
+	regexp.MustCompile(`(?i)waiting for (your )?(input|approval|confirmation)`),
+	regexp.MustCompile(`❯\s*1\.\s*Yes`),
+}
+
+// ShellCommands are foreground commands that mean nothing else is running in the pane
+var ShellCommands = map[string]bool{
+	"bash": true, "-bash": true, "zsh": true, "-zsh": true, "sh": true,
+	"fish": true, "dash": true, "ksh": true, "tcsh": true, "login": true,
+}
+
+// AgentCommands are foreground commands treated as AI agents
+// When one of these is replaced by a shell, the agent is reported as crashed
+var AgentCommands = map[string]bool{
+	"claude": true, "node": true, "codex": true, "aider": true,
+	"gemini": true, "opencode": true, "amp": true, "gt": true,
+}
+
+// StateSample is one observation of a session's active pane
+type StateSample struct {
+	Screen      string // Visible pane content
+	Command     string // Foreground command
+	Dead        bool   // Pane process has exited (remain-on-exit)
+	Changed     bool   // Screen or activity changed since the previous sample
+	AgentExited bool   // An agent command was replaced by a shell and nothing has run since
+}
+
+// ClassifySession derives an agent state from a sample
+func ClassifySession(sample StateSample) string {
+	if sample.Dead {
+		return StateCrashed
+	}
+
+	tail := tailLines(sample.Screen, stateTailLines)
+	for _, pattern := range WaitingPatterns {
+		if pattern.MatchString(tail) {
+			return StateWaiting
+		}
+	}
+
+	if sample.Changed {
+		return StateWorking
+	}
+	if sample.AgentExited && ShellCommands[sample.Command] {
+		return StateCrashed
+	}
+	return StateIdle
+}
+
+// tailLines returns the last n non-empty lines of screen
+func tailLines(screen string, n int) string {
+	lines := strings.Split(screen, "\n")
+	var tail []string
+	for i := len(lines) - 1; i >= 0 && len(tail) < n; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			tail = append([]string{strings.TrimRight(lines[i], " \t\r")}, tail...)
+		}
+	}
+	return strings.Join(tail, "\n")
+}
@@ -0,0 +1,37 @@
+package core
+
+import "testing"
+
+func TestClassifySession(t *testing.T) {
+	tests := []struct {
+		name     string
+		sample   StateSample
+		expected string
+	}{
+		{"dead pane", StateSample{Dead: true, Changed: true}, StateCrashed},
+		{"output changing", StateSample{Screen: "Compiling...\n", Command: "claude", Changed: true}, StateWorking},
+		{"quiet agent", StateSample{Screen: "> \n", Command: "claude"}, StateIdle},
+		{"quiet shell", StateSample{Screen: "user@host:~$ \n", Command: "bash"}, StateIdle},
+		{"y/n prompt", StateSample{Screen: "Overwrite file? [y/N]\n\n", Command: "node", Changed: true}, StateWaiting},
+		{"permission prompt", StateSample{Screen: "Do you want to make this edit to main.go?\n❯ 1. Yes\n  2. No\n", Command: "claude"}, StateWaiting},
+		{"prompt scrolled away", StateSample{Screen: "Proceed? (y/n)\n" + repeatLines("output", 20), Command: "bash"}, StateIdle},
+		{"agent exited to shell", StateSample{Screen: "user@host:~$ \n", Command: "bash", AgentExited: true}, StateCrashed},
+		{"agent exited, shell in use", StateSample{Screen: "user@host:~$ ls\n", Command: "bash", Changed: true, AgentExited: true}, StateWorking},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifySession(tt.sample); got != tt.expected {
+				t.Errorf("ClassifySession() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func repeatLines(line string, n int) string {
+	out := ""
+	for i := 0; i < n; i++ {
+		out += line + "\n"
+	}
+	return out
+}
@@ -0,0 +1,220 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// RecordingOption is the tmux user option holding the recorded pane ID and cast file name
+// e.g. "%12 20260101-120000.cast"
+const RecordingOption = "@chrote-recording"
+
+// CastFileRegex validates recording file names within a session's recording dir
+var CastFileRegex = regexp.MustCompile(`^\d{8}-\d{6}\.cast$`)
+
+// castChunkSize is the most pane output read into one asciicast event
+const castChunkSize = 32 * 1024
+
+// CastHeader is the first line of an asciicast v2 file
+type CastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// RecordingInfo describes one stored recording
+type RecordingInfo struct {
+	Session  string  `json:"session"`
+	Name     string  `json:"name"`
+	Size     int64   `json:"size"`
+	Started  string  `json:"started"`
+	Duration float64 `json:"duration"` // Seconds until the last write
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	Active   bool    `json:"active"` // Still being written; set by the API
+}
+
+// GetRecordingsDir returns the directory session recordings are written to
+// Reads from CHROTE_RECORDINGS_DIR env var, defaults to <config dir>/recordings
+func GetRecordingsDir() string {
+	if dir := os.Getenv("CHROTE_RECORDINGS_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(GetConfigDir(), "recordings")
+}
+
+// SessionRecordingDir returns the recording directory for a (validated) session name
+func SessionRecordingDir(session string) string {
+	return filepath.Join(GetRecordingsDir(), session)
+}
+
+// NewCastName returns the file name for a recording started at t
+func NewCastName(t time.Time) string {
+	return t.UTC().Format("20060102-150405") + ".cast"
+}
+
+// ReadCastHeader reads the header of an asciicast file
+func ReadCastHeader(path string) (CastHeader, error) {
+	var header CastHeader
+	f, err := os.Open(path)
+	if err != nil {
+		return header, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return header, err
+	}
+	if err := json.Unmarshal(line, &header); err != nil {
+		return header, fmt.Errorf("invalid asciicast header: %v", err)
+	}
+	if header.Version != 2 {
+		return header, fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+	return header, nil
+}
+
+// RecordCast copies terminal output from in to the asciicast file at path until in closes
+// A new file gets the given header; an existing one is appended to, with event
+// times continuing from its own header so a restarted recorder resumes the cast
+func RecordCast(in io.Reader, path string, header CastHeader, now func() time.Time) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		header.Version = 2
+		if header.Timestamp == 0 {
+			header.Timestamp = now().Unix()
+		}
+		line, _ := json.Marshal(header)
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	} else if existing, err := ReadCastHeader(path); err == nil {
+		header = existing
+	} else {
+		return err
+	}
+	start := time.Unix(header.Timestamp, 0)
+
+	buf := make([]byte, castChunkSize)
+	var pending []byte
+	for {
+		n, readErr := in.Read(buf)
+		if n > 0 {
+			data := append(pending, buf[:n]...)
+			// Hold back a multi-byte character split across reads
+			cut := len(data)
+			for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+				if utf8.RuneStart(data[i]) {
+					if !utf8.FullRune(data[i:]) {
+						cut = i
+					}
+					break
+				}
+			}
+			pending = append([]byte(nil), data[cut:]...)
+			if err := writeCastEvent(f, now().Sub(start), data[:cut]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			if len(pending) > 0 {
+				return writeCastEvent(f, now().Sub(start), pending)
+			}
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// writeCastEvent appends one output event
+func writeCastEvent(w io.Writer, elapsed time.Duration, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	text, _ := json.Marshal(string(data))
+	_, err := fmt.Fprintf(w, "[%.6f, \"o\", %s]\n", elapsed.Seconds(), text)
+	return err
+}
+
+// ListRecordings returns the stored recordings of every session, most recent first
+func ListRecordings() ([]RecordingInfo, error) {
+	recordings := []RecordingInfo{}
+	sessions, err := os.ReadDir(GetRecordingsDir())
+	if os.IsNotExist(err) {
+		return recordings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sessionDir := range sessions {
+		session := sessionDir.Name()
+		if !sessionDir.IsDir() || !SessionNameRegex.MatchString(session) {
+			continue
+		}
+		entries, err := os.ReadDir(SessionRecordingDir(session))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !CastFileRegex.MatchString(entry.Name()) {
+				continue
+			}
+			path := filepath.Join(SessionRecordingDir(session), entry.Name())
+			header, err := ReadCastHeader(path)
+			if err != nil {
+				continue
+			}
+			fi, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			started := time.Unix(header.Timestamp, 0)
+			recordings = append(recordings, RecordingInfo{
+				Session:  session,
+				Name:     entry.Name(),
+				Size:     fi.Size(),
+				Started:  started.UTC().Format(time.RFC3339),
+				Duration: max(0, fi.ModTime().Sub(started).Seconds()),
+				Width:    header.Width,
+				Height:   header.Height,
+			})
+		}
+	}
+
+	sort.SliceStable(recordings, func(i, j int) bool {
+		return recordings[i].Started > recordings[j].Started
+	})
+	return recordings, nil
+}
+
+// ParseRecordingOption splits a RecordingOption value into pane ID and cast file name
+func ParseRecordingOption(value string) (string, string) {
+	pane, file, _ := strings.Cut(strings.TrimSpace(value), " ")
+	return pane, file
+}
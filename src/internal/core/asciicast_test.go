@@ -0,0 +1,107 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// steppingClock returns a clock that advances by step on every call
+func steppingClock(start time.Time, step time.Duration) func() time.Time {
+	now := start
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func readCastLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestRecordCast(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "20260101-120000.cast")
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// One byte at a time splits the multi-byte character across reads
+	in := iotest.OneByteReader(strings.NewReader("hé"))
+	header := CastHeader{Width: 80, Height: 24, Timestamp: start.Unix(), Title: "dev"}
+	if err := RecordCast(in, path, header, steppingClock(start, time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readCastLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 events, got %q", lines)
+	}
+	got, err := ReadCastHeader(path)
+	if err != nil || got.Version != 2 || got.Width != 80 || got.Title != "dev" {
+		t.Errorf("Unexpected header %+v (%v)", got, err)
+	}
+	var event []interface{}
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event[1] != "o" || event[2] != "é" {
+		t.Errorf("Expected the split character in one event, got %v", event)
+	}
+
+	// A restarted recorder appends, keeping the original start time
+	later := start.Add(time.Minute)
+	if err := RecordCast(strings.NewReader("more"), path, CastHeader{Width: 100}, steppingClock(later, 0)); err != nil {
+		t.Fatal(err)
+	}
+	lines = readCastLines(t, path)
+	if len(lines) != 4 || !strings.HasPrefix(lines[3], "[60.000000, \"o\", \"more\"]") {
+		t.Errorf("Expected appended event at 60s, got %q", lines[len(lines)-1])
+	}
+}
+
+func TestReadCastHeader_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.cast")
+	os.WriteFile(path, []byte(`{"version":1}`+"\n"), 0600)
+	if _, err := ReadCastHeader(path); err == nil {
+		t.Error("Expected error for asciicast v1")
+	}
+}
+
+func TestListRecordings(t *testing.T) {
+	t.Setenv("CHROTE_RECORDINGS_DIR", t.TempDir())
+
+	write := func(session, name string, ts int64) {
+		dir := SessionRecordingDir(session)
+		os.MkdirAll(dir, 0700)
+		header, _ := json.Marshal(CastHeader{Version: 2, Width: 80, Height: 24, Timestamp: ts})
+		os.WriteFile(filepath.Join(dir, name), append(header, '\n'), 0600)
+	}
+	write("a", "20260101-000000.cast", 1767225600)
+	write("b", "20260102-000000.cast", 1767312000)
+	write("b", "notes.txt", 0)
+
+	recordings, err := ListRecordings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordings) != 2 || recordings[0].Session != "b" || recordings[1].Session != "a" {
+		t.Errorf("Expected newest first, got %+v", recordings)
+	}
+}
+
+func TestParseRecordingOption(t *testing.T) {
+	pane, file := ParseRecordingOption("%12 20260101-120000.cast\n")
+	if pane != "%12" || file != "20260101-120000.cast" {
+		t.Errorf("Unexpected parse: %q %q", pane, file)
+	}
+	if pane, file := ParseRecordingOption(""); pane != "" || file != "" {
+		t.Errorf("Expected empty parse, got %q %q", pane, file)
+	}
+}
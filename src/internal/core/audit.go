@@ -0,0 +1,45 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditFile is the config file forced and destructive operations are appended to, one JSON object per line
+const AuditFile = "audit.jsonl"
+
+// AuditEntry records one audited operation
+type AuditEntry struct {
+	Timestamp string `json:"timestamp"`
+	Action    string `json:"action"`
+	Session   string `json:"session,omitempty"`
+	Remote    string `json:"remote,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// AppendAudit appends an entry to the audit log in the config dir, stamping it if needed
+func AppendAudit(entry AuditEntry) error {
+	if entry.Timestamp == "" {
+		entry.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	dir := GetConfigDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, AuditFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
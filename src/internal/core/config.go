@@ -0,0 +1,65 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// GetConfigDir returns the directory for server-side config files
+// Reads from CHROTE_CONFIG_DIR env var, defaults to ~/.chrote
+func GetConfigDir() string {
+	if dir := os.Getenv("CHROTE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".chrote")
+	}
+	return ".chrote"
+}
+
+// LoadConfigFile decodes the named JSON file in the config dir into v
+// A missing file is not an error and leaves v untouched
+func LoadConfigFile(name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(GetConfigDir(), name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SaveConfigFile writes v as indented JSON to the named file in the config dir
+// name may include a subdirectory, which is created as needed
+// The file is written to a temp file and renamed so readers never see a partial write
+func SaveConfigFile(name string, v interface{}) error {
+	path := filepath.Join(GetConfigDir(), name)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
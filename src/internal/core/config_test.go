@@ -0,0 +1,63 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigFile_RoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+	t.Setenv("CHROTE_CONFIG_DIR", dir)
+
+	var missing []string
+	if err := LoadConfigFile("missing.json", &missing); err != nil || missing != nil {
+		t.Fatalf("Missing file should load as nothing, got %v, %v", missing, err)
+	}
+
+	if err := SaveConfigFile("things.json", []string{"a", "b"}); err != nil {
+		t.Fatalf("SaveConfigFile failed: %v", err)
+	}
+
+	var loaded []string
+	if err := LoadConfigFile("things.json", &loaded); err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0] != "a" || loaded[1] != "b" {
+		t.Errorf("Unexpected round trip result: %v", loaded)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only things.json in the config dir, got %d entries", len(entries))
+	}
+}
+
+func TestConfigFile_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CHROTE_CONFIG_DIR", dir)
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{not json"), 0600)
+
+	var v map[string]string
+	if err := LoadConfigFile("bad.json", &v); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestAppendAudit(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CHROTE_CONFIG_DIR", dir)
+
+	AppendAudit(AuditEntry{Action: "force-kill", Session: "hq-mayor"})
+	AppendAudit(AuditEntry{Action: "force-kill", Session: "hq-deacon"})
+
+	data, err := os.ReadFile(filepath.Join(dir, AuditFile))
+	if err != nil {
+		t.Fatalf("Audit log not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"session":"hq-deacon"`) || !strings.Contains(lines[0], `"timestamp":"`) {
+		t.Errorf("Unexpected audit log: %s", data)
+	}
+}
@@ -0,0 +1,195 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// GroupingFile is the config file grouping rules are stored in
+const GroupingFile = "grouping.json"
+
+// maxGroupingRules bounds the number of configured grouping rules
+const maxGroupingRules = 100
+
+// Grouping rule types
+const (
+	RuleTypePrefix = "prefix" // Session name starts with Prefix
+	RuleTypeRegex  = "regex"  // Session name matches Pattern
+	RuleTypeOption = "option" // Session has the tmux user option Option set
+)
+
+// GroupingRule assigns matching sessions to a group
+// Group may be left empty: prefix rules then use the prefix without its trailing
+// separator, regex rules the first capture group (or the whole match) and option
+// rules the option's value. For regex rules Group may reference captures ($1, ${name})
+type GroupingRule struct {
+	Type    string `json:"type"`
+	Prefix  string `json:"prefix,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Option  string `json:"option,omitempty"`
+	Group   string `json:"group,omitempty"`
+}
+
+// GroupingConfig is the operator-defined grouping configuration
+// Rules are tried in order before the built-in conventions; the first match wins
+type GroupingConfig struct {
+	Rules      []GroupingRule `json:"rules"`
+	Priorities map[string]int `json:"priorities,omitempty"` // Lower sorts first; built-ins are hq 0, main 1, gt-* 3, others 4
+}
+
+// OptionNameRegex validates tmux user option names
+var OptionNameRegex = regexp.MustCompile(`^@[a-zA-Z0-9_-]+$`)
+
+var (
+	groupingMu       sync.RWMutex
+	groupingConfig   = GroupingConfig{Rules: []GroupingRule{}}
+	groupingPatterns []*regexp.Regexp // compiled Pattern per rule, nil for non-regex rules
+)
+
+// ValidateGroupingConfig validates grouping rules and priorities
+func ValidateGroupingConfig(cfg GroupingConfig) (bool, string) {
+	if len(cfg.Rules) > maxGroupingRules {
+		return false, "too many rules (max 100)."
+	}
+	for i, rule := range cfg.Rules {
+		prefix := fmt.Sprintf("rule %d: ", i+1)
+		switch rule.Type {
+		case RuleTypePrefix:
+			if rule.Prefix == "" {
+				return false, prefix + "prefix is required."
+			}
+		case RuleTypeRegex:
+			if rule.Pattern == "" {
+				return false, prefix + "pattern is required."
+			}
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return false, prefix + "Invalid pattern: " + err.Error()
+			}
+		case RuleTypeOption:
+			if !OptionNameRegex.MatchString(rule.Option) {
+				return false, prefix + "option must be a tmux user option like @project."
+			}
+		default:
+			return false, prefix + "Invalid type. Use prefix, regex or option."
+		}
+		if rule.Group != "" && !strings.Contains(rule.Group, "$") {
+			if valid, errMsg := ValidateSessionName(rule.Group, "group"); !valid {
+				return false, prefix + errMsg
+			}
+		}
+	}
+	for group := range cfg.Priorities {
+		if valid, errMsg := ValidateSessionName(group, "priority group"); !valid {
+			return false, errMsg
+		}
+	}
+	return true, ""
+}
+
+// SetGroupingConfig validates and activates a grouping configuration
+func SetGroupingConfig(cfg GroupingConfig) error {
+	if valid, errMsg := ValidateGroupingConfig(cfg); !valid {
+		return fmt.Errorf("%s", errMsg)
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = []GroupingRule{}
+	}
+
+	patterns := make([]*regexp.Regexp, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		if rule.Type == RuleTypeRegex {
+			patterns[i] = regexp.MustCompile(rule.Pattern)
+		}
+	}
+
+	groupingMu.Lock()
+	groupingConfig = cfg
+	groupingPatterns = patterns
+	groupingMu.Unlock()
+	return nil
+}
+
+// GetGroupingConfig returns the active grouping configuration
+func GetGroupingConfig() GroupingConfig {
+	groupingMu.RLock()
+	defer groupingMu.RUnlock()
+	return groupingConfig
+}
+
+// GroupingOptions returns the tmux user options referenced by option rules
+func GroupingOptions() []string {
+	groupingMu.RLock()
+	defer groupingMu.RUnlock()
+
+	var options []string
+	for _, rule := range groupingConfig.Rules {
+		if rule.Type == RuleTypeOption {
+			options = append(options, rule.Option)
+		}
+	}
+	return options
+}
+
+// CategorizeSessionWithOptions determines the group for a session from the
+// configured rules, falling back to the built-in naming conventions
+// options maps tmux user option names to the session's values
+func CategorizeSessionWithOptions(name string, options map[string]string) string {
+	groupingMu.RLock()
+	defer groupingMu.RUnlock()
+
+	for i, rule := range groupingConfig.Rules {
+		if group := applyGroupingRule(rule, groupingPatterns[i], name, options); group != "" {
+			return group
+		}
+	}
+	return categorizeByConvention(name)
+}
+
+// applyGroupingRule returns the group a rule assigns to a session, or "" if it doesn't match
+func applyGroupingRule(rule GroupingRule, pattern *regexp.Regexp, name string, options map[string]string) string {
+	switch rule.Type {
+	case RuleTypePrefix:
+		if !strings.HasPrefix(name, rule.Prefix) {
+			return ""
+		}
+		if rule.Group != "" {
+			return rule.Group
+		}
+		return strings.TrimRight(rule.Prefix, "-_.")
+
+	case RuleTypeRegex:
+		match := pattern.FindStringSubmatchIndex(name)
+		if match == nil {
+			return ""
+		}
+		if rule.Group != "" {
+			return string(pattern.ExpandString(nil, rule.Group, name, match))
+		}
+		if len(match) >= 4 && match[2] >= 0 {
+			return name[match[2]:match[3]]
+		}
+		return name[match[0]:match[1]]
+
+	case RuleTypeOption:
+		value := options[rule.Option]
+		if value == "" {
+			return ""
+		}
+		if rule.Group != "" {
+			return rule.Group
+		}
+		return value
+	}
+	return ""
+}
+
+// configuredGroupPriority returns the configured priority for a group, if any
+func configuredGroupPriority(group string) (int, bool) {
+	groupingMu.RLock()
+	defer groupingMu.RUnlock()
+	p, ok := groupingConfig.Priorities[group]
+	return p, ok
+}
@@ -0,0 +1,98 @@
+package core
+
+import "testing"
+
+func TestValidateGroupingConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  GroupingRule
+		valid bool
+	}{
+		{"prefix", GroupingRule{Type: RuleTypePrefix, Prefix: "web-"}, true},
+		{"empty prefix", GroupingRule{Type: RuleTypePrefix}, false},
+		{"regex", GroupingRule{Type: RuleTypeRegex, Pattern: `^(\w+)-agent-\d+$`, Group: "agents-$1"}, true},
+		{"bad regex", GroupingRule{Type: RuleTypeRegex, Pattern: `(`}, false},
+		{"option", GroupingRule{Type: RuleTypeOption, Option: "@project"}, true},
+		{"option without @", GroupingRule{Type: RuleTypeOption, Option: "project"}, false},
+		{"unknown type", GroupingRule{Type: "suffix"}, false},
+		{"bad literal group", GroupingRule{Type: RuleTypePrefix, Prefix: "x", Group: "a b"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, errMsg := ValidateGroupingConfig(GroupingConfig{Rules: []GroupingRule{tt.rule}})
+			if valid != tt.valid {
+				t.Errorf("ValidateGroupingConfig() = %v (%s), expected %v", valid, errMsg, tt.valid)
+			}
+		})
+	}
+
+	if valid, _ := ValidateGroupingConfig(GroupingConfig{Priorities: map[string]int{"bad group": 1}}); valid {
+		t.Error("Expected invalid priority group name to be rejected")
+	}
+}
+
+func TestCategorizeSession_ConfiguredRules(t *testing.T) {
+	defer SetGroupingConfig(GroupingConfig{})
+
+	err := SetGroupingConfig(GroupingConfig{
+		Rules: []GroupingRule{
+			{Type: RuleTypeOption, Option: "@project"},
+			{Type: RuleTypePrefix, Prefix: "web-"},
+			{Type: RuleTypePrefix, Prefix: "api_", Group: "backend"},
+			{Type: RuleTypeRegex, Pattern: `^(?P<team>[a-z]+)\.\d+$`, Group: "team-${team}"},
+			{Type: RuleTypeRegex, Pattern: `^ml-(\w+)-`},
+		},
+		Priorities: map[string]int{"web": -1, "hq": 9},
+	})
+	if err != nil {
+		t.Fatalf("SetGroupingConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		session  string
+		options  map[string]string
+		expected string
+	}{
+		{"web-frontend", nil, "web"},
+		{"api_users", nil, "backend"},
+		{"infra.12", nil, "team-infra"},
+		{"ml-vision-3", nil, "vision"},
+		{"hq-mayor", nil, "hq"},
+		{"gt-gastown-jack", nil, "gt-gastown"},
+		{"random", nil, "other"},
+		{"web-frontend", map[string]string{"@project": "chrote"}, "chrote"},
+		{"random", map[string]string{"@project": ""}, "other"},
+	}
+
+	for _, tt := range tests {
+		if got := CategorizeSessionWithOptions(tt.session, tt.options); got != tt.expected {
+			t.Errorf("CategorizeSessionWithOptions(%q, %v) = %q, expected %q", tt.session, tt.options, got, tt.expected)
+		}
+	}
+
+	if p := GetGroupPriority("web"); p != -1 {
+		t.Errorf("GetGroupPriority(web) = %d, expected configured -1", p)
+	}
+	if p := GetGroupPriority("hq"); p != 9 {
+		t.Errorf("GetGroupPriority(hq) = %d, expected configured 9", p)
+	}
+	if p := GetGroupPriority("main"); p != 1 {
+		t.Errorf("GetGroupPriority(main) = %d, expected built-in 1", p)
+	}
+
+	if options := GroupingOptions(); len(options) != 1 || options[0] != "@project" {
+		t.Errorf("GroupingOptions() = %v, expected [@project]", options)
+	}
+}
+
+func TestSetGroupingConfig_Invalid(t *testing.T) {
+	defer SetGroupingConfig(GroupingConfig{})
+
+	if err := SetGroupingConfig(GroupingConfig{Rules: []GroupingRule{{Type: "bogus"}}}); err == nil {
+		t.Error("Expected invalid config to be rejected")
+	}
+	if got := CategorizeSession("hq-mayor"); got != "hq" {
+		t.Errorf("Rejected config should leave defaults in place, got %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxInputBytes caps the size of text or paste payloads sent to a pane
+const MaxInputBytes = 64 * 1024
+
+// KeyNameRegex validates tmux key names accepted by send-keys
+// Allows optional C-/M-/S- modifiers on named keys, function keys and single characters
+var KeyNameRegex = regexp.MustCompile(`^([CMS]-)*(Enter|Escape|Tab|BTab|BSpace|Space|Up|Down|Left|Right|Home|End|PageUp|PageDown|PPage|NPage|Insert|Delete|IC|DC|F[1-9]|F1[0-2]|[a-zA-Z0-9]|[\[\]\\/@^_])$`)
+
+// PromptRegex matches a line that ends in a typical shell or agent prompt
+var PromptRegex = regexp.MustCompile(`[$#%>❯›]\s*$`)
+
+// ValidateKeyName validates a tmux key name such as Enter, Escape or C-c
+func ValidateKeyName(key string) (bool, string) {
+	if key == "" {
+		return false, "key name is required."
+	}
+	if !KeyNameRegex.MatchString(key) {
+		return false, "Invalid key name: " + key + ". Use tmux key names like Enter, Escape, Tab or C-c."
+	}
+	return true, ""
+}
+
+// ValidateInputText validates literal text destined for a pane
+func ValidateInputText(text, paramName string) (bool, string) {
+	if len(text) > MaxInputBytes {
+		return false, paramName + " too long (max " + strconv.Itoa(MaxInputBytes) + " bytes)."
+	}
+	if !utf8.ValidString(text) {
+		return false, paramName + " must be valid UTF-8."
+	}
+	if strings.ContainsRune(text, 0) {
+		return false, paramName + " must not contain NUL bytes."
+	}
+	return true, ""
+}
+
+// LastNonEmptyLine returns the last line of captured pane output that is not blank
+func LastNonEmptyLine(screen string) string {
+	lines := strings.Split(screen, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return strings.TrimRight(lines[i], " \t\r")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateKeyName(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"Enter", true},
+		{"Escape", true},
+		{"C-c", true},
+		{"M-x", true},
+		{"C-M-Up", true},
+		{"F12", true},
+		{"y", true},
+		{"", false},
+		{"F13", false},
+		{"Enter Enter", false},
+		{"; kill-server", false},
+		{"X-c", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			valid, _ := ValidateKeyName(tt.key)
+			if valid != tt.valid {
+				t.Errorf("ValidateKeyName(%q) = %v, expected %v", tt.key, valid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidateInputText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		valid bool
+	}{
+		{"empty", "", true},
+		{"plain", "echo hello", true},
+		{"multiline", "line1\nline2", true},
+		{"unicode", "héllo ❯", true},
+		{"nul byte", "bad\x00text", false},
+		{"invalid utf8", "\xff\xfe", false},
+		{"too long", strings.Repeat("a", MaxInputBytes+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, _ := ValidateInputText(tt.text, "text")
+			if valid != tt.valid {
+				t.Errorf("ValidateInputText(%q) = %v, expected %v", tt.name, valid, tt.valid)
+			}
+		})
+	}
+}
+
+func TestLastNonEmptyLine(t *testing.T) {
+	screen := "output line\nuser@host:~$ \n\n\n"
+	if got := LastNonEmptyLine(screen); got != "user@host:~$" {
+		t.Errorf("LastNonEmptyLine() = %q, expected %q", got, "user@host:~$")
+	}
+	if got := LastNonEmptyLine("\n \n"); got != "" {
+		t.Errorf("LastNonEmptyLine() of blank screen = %q, expected empty", got)
+	}
+}
+
+func TestPromptRegex(t *testing.T) {
+	prompts := []string{"user@host:~$", "root@box:/# ", "> ", "❯", "zsh %"}
+	for _, p := range prompts {
+		if !PromptRegex.MatchString(p) {
+			t.Errorf("PromptRegex should match %q", p)
+		}
+	}
+	if PromptRegex.MatchString("Compiling module 3/10...") {
+		t.Error("PromptRegex should not match progress output")
+	}
+}
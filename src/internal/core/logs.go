@@ -0,0 +1,214 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LoggingOption is the tmux user option holding the ID of the pane a session logs
+const LoggingOption = "@chrote-logging"
+
+// CurrentLogFile is the file a logged pane is currently appending to
+// Rotated logs are named by their rotation time, e.g. 20260101-120000.log
+const CurrentLogFile = "current.log"
+
+// LogFileRegex validates log file names within a session's log dir
+var LogFileRegex = regexp.MustCompile(`^(current|\d{8}-\d{6})\.log$`)
+
+// ansiRegex matches terminal escape sequences (CSI, OSC, charset selection and two-byte escapes)
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;?<=>!]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[@-Z\\-_]`)
+
+// LogFile describes one log file of a session
+type LogFile struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Modified string `json:"modified"`
+	Current  bool   `json:"current"`
+}
+
+// LogMatch is one search hit in a session's logs
+type LogMatch struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// GetLogDir returns the directory session logs are written to
+// Reads from CHROTE_LOG_DIR env var, defaults to <config dir>/logs
+func GetLogDir() string {
+	if dir := os.Getenv("CHROTE_LOG_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(GetConfigDir(), "logs")
+}
+
+// SessionLogDir returns the log directory for a (validated) session name
+func SessionLogDir(session string) string {
+	return filepath.Join(GetLogDir(), session)
+}
+
+// StripANSI removes terminal escape sequences and carriage returns from text
+func StripANSI(text string) string {
+	return strings.ReplaceAll(ansiRegex.ReplaceAllString(text, ""), "\r", "")
+}
+
+// ListLogSessions returns the names of sessions that have logs, sorted
+func ListLogSessions() ([]string, error) {
+	sessions := []string{}
+	entries, err := os.ReadDir(GetLogDir())
+	if os.IsNotExist(err) {
+		return sessions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && SessionNameRegex.MatchString(entry.Name()) {
+			sessions = append(sessions, entry.Name())
+		}
+	}
+	sort.Strings(sessions)
+	return sessions, nil
+}
+
+// ListLogFiles returns a session's log files, oldest first with the current log last
+func ListLogFiles(session string) ([]LogFile, error) {
+	files := []LogFile{}
+	entries, err := os.ReadDir(SessionLogDir(session))
+	if os.IsNotExist(err) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !LogFileRegex.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, LogFile{
+			Name:     entry.Name(),
+			Size:     info.Size(),
+			Modified: info.ModTime().UTC().Format(time.RFC3339),
+			Current:  entry.Name() == CurrentLogFile,
+		})
+	}
+
+	// Rotated names sort chronologically, and "current" sorts after digits
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// SearchLogs searches a session's logs line by line, oldest first, with escape sequences stripped
+// Returns at most limit matches and whether more were left unreported
+func SearchLogs(session string, pattern *regexp.Regexp, limit int) ([]LogMatch, bool, error) {
+	matches := []LogMatch{}
+	files, err := ListLogFiles(session)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, file := range files {
+		f, err := os.Open(filepath.Join(SessionLogDir(session), file.Name))
+		if err != nil {
+			continue
+		}
+		truncated, err := searchLogFile(f, file.Name, pattern, limit, &matches)
+		f.Close()
+		if err != nil {
+			return nil, false, err
+		}
+		if truncated {
+			return matches, true, nil
+		}
+	}
+	return matches, false, nil
+}
+
+// searchLogFile appends the matches in one log file, reporting whether the limit was hit
+func searchLogFile(r io.Reader, name string, pattern *regexp.Regexp, limit int, matches *[]LogMatch) (bool, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := StripANSI(scanner.Text())
+		if !pattern.MatchString(text) {
+			continue
+		}
+		if len(*matches) >= limit {
+			return true, nil
+		}
+		*matches = append(*matches, LogMatch{File: name, Line: line, Text: text})
+	}
+	return false, scanner.Err()
+}
+
+// RotateLog archives a session's current log once it reaches maxBytes and prunes old archives
+// The current log is copied and truncated in place, since the logging process keeps it open
+// Returns whether a rotation happened
+func RotateLog(session string, maxBytes int64, keep int, now time.Time) (bool, error) {
+	dir := SessionLogDir(session)
+	current := filepath.Join(dir, CurrentLogFile)
+
+	info, err := os.Stat(current)
+	if err != nil || info.Size() < maxBytes {
+		return false, nil
+	}
+
+	archive := filepath.Join(dir, now.UTC().Format("20060102-150405")+".log")
+	if err := copyFile(current, archive); err != nil {
+		return false, err
+	}
+	if err := os.Truncate(current, 0); err != nil {
+		return false, err
+	}
+
+	files, err := ListLogFiles(session)
+	if err != nil {
+		return true, err
+	}
+	var archives []string
+	for _, file := range files {
+		if !file.Current {
+			archives = append(archives, file.Name)
+		}
+	}
+	for len(archives) > keep {
+		os.Remove(filepath.Join(dir, archives[0]))
+		archives = archives[1:]
+	}
+	return true, nil
+}
+
+// copyFile copies src to a new file at dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"\x1b[1;32mgreen\x1b[0m", "green"},
+		{"\x1b]0;title\x07prompt$ ", "prompt$ "},
+		{"\x1b(Bline\r", "line"},
+		{"\x1b[?2004hls\x1b[?2004l", "ls"},
+	}
+
+	for _, tt := range tests {
+		if got := StripANSI(tt.input); got != tt.expected {
+			t.Errorf("StripANSI(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func writeLog(t *testing.T, session, name, content string) {
+	t.Helper()
+	dir := SessionLogDir(session)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListLogFiles_Order(t *testing.T) {
+	t.Setenv("CHROTE_LOG_DIR", t.TempDir())
+	writeLog(t, "dev", CurrentLogFile, "c")
+	writeLog(t, "dev", "20260102-000000.log", "b")
+	writeLog(t, "dev", "20260101-000000.log", "a")
+	writeLog(t, "dev", "notes.txt", "ignored")
+
+	files, err := ListLogFiles("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	expected := []string{"20260101-000000.log", "20260102-000000.log", CurrentLogFile}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, names)
+		}
+	}
+	if !files[2].Current {
+		t.Error("Expected current.log to be marked current")
+	}
+
+	sessions, err := ListLogSessions()
+	if err != nil || len(sessions) != 1 || sessions[0] != "dev" {
+		t.Errorf("Expected [dev], got %v (%v)", sessions, err)
+	}
+}
+
+func TestSearchLogs(t *testing.T) {
+	t.Setenv("CHROTE_LOG_DIR", t.TempDir())
+	writeLog(t, "dev", "20260101-000000.log", "build \x1b[31mfailed\x1b[0m\nok\n")
+	writeLog(t, "dev", CurrentLogFile, "test failed\nbuild failed again\n")
+
+	matches, truncated, err := SearchLogs("dev", regexp.MustCompile("failed"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truncated || len(matches) != 3 {
+		t.Fatalf("Expected 3 matches, got %d (truncated %v)", len(matches), truncated)
+	}
+	if matches[0].File != "20260101-000000.log" || matches[0].Line != 1 || matches[0].Text != "build failed" {
+		t.Errorf("Unexpected first match: %+v", matches[0])
+	}
+	if matches[2].File != CurrentLogFile || matches[2].Line != 2 {
+		t.Errorf("Unexpected last match: %+v", matches[2])
+	}
+
+	matches, truncated, err = SearchLogs("dev", regexp.MustCompile("failed"), 2)
+	if err != nil || !truncated || len(matches) != 2 {
+		t.Errorf("Expected 2 truncated matches, got %d (truncated %v, err %v)", len(matches), truncated, err)
+	}
+
+	matches, _, err = SearchLogs("missing", regexp.MustCompile("x"), 10)
+	if err != nil || len(matches) != 0 {
+		t.Errorf("Expected no matches for missing session, got %v (%v)", matches, err)
+	}
+}
+
+func TestRotateLog(t *testing.T) {
+	t.Setenv("CHROTE_LOG_DIR", t.TempDir())
+	writeLog(t, "dev", CurrentLogFile, "small")
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if rotated, err := RotateLog("dev", 100, 2, now); rotated || err != nil {
+		t.Fatalf("Expected no rotation below the limit, got %v (%v)", rotated, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		writeLog(t, "dev", CurrentLogFile, "0123456789")
+		rotated, err := RotateLog("dev", 10, 2, now.Add(time.Duration(i)*time.Second))
+		if !rotated || err != nil {
+			t.Fatalf("Expected rotation %d, got %v (%v)", i, rotated, err)
+		}
+	}
+
+	files, err := ListLogFiles("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("Expected 2 archives and current log, got %+v", files)
+	}
+	if files[0].Name != "20260101-120001.log" || files[1].Name != "20260101-120002.log" {
+		t.Errorf("Expected oldest archive pruned, got %+v", files)
+	}
+	if files[2].Size != 0 {
+		t.Errorf("Expected current log truncated, got size %d", files[2].Size)
+	}
+	if files[0].Size != 10 {
+		t.Errorf("Expected archive to hold the rotated content, got size %d", files[0].Size)
+	}
+}
@@ -0,0 +1,48 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LineMatch is one matching line in a block of text, with surrounding lines for context
+type LineMatch struct {
+	Line   int      `json:"line"` // 1-based
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// FindLineMatches returns up to limit lines of text matching pattern, each with
+// up to context lines before and after it, and whether more matches were left out
+// Trailing blank lines (the unused part of a pane) are ignored
+func FindLineMatches(text string, pattern *regexp.Regexp, context, limit int) ([]LineMatch, bool) {
+	matches := []LineMatch{}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	for i, line := range lines {
+		if !pattern.MatchString(line) {
+			continue
+		}
+		if len(matches) >= limit {
+			return matches, true
+		}
+		match := LineMatch{Line: i + 1, Text: line}
+		if context > 0 {
+			start := max(0, i-context)
+			end := min(len(lines), i+1+context)
+			if start < i {
+				match.Before = append([]string(nil), lines[start:i]...)
+			}
+			if i+1 < end {
+				match.After = append([]string(nil), lines[i+1:end]...)
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches, false
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFindLineMatches(t *testing.T) {
+	text := "one\ntwo\nCONFLICT (content): Merge conflict in a.go\nthree\nfour\nmerge conflict again\n\n\n"
+	pattern := regexp.MustCompile("(?i)merge conflict")
+
+	matches, truncated := FindLineMatches(text, pattern, 1, 10)
+	if truncated || len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %d (truncated %v)", len(matches), truncated)
+	}
+	first := matches[0]
+	if first.Line != 3 || !strings.HasPrefix(first.Text, "CONFLICT") {
+		t.Errorf("Unexpected first match: %+v", first)
+	}
+	if len(first.Before) != 1 || first.Before[0] != "two" || len(first.After) != 1 || first.After[0] != "three" {
+		t.Errorf("Unexpected context: %+v", first)
+	}
+	// Trailing blank lines are not context
+	if matches[1].Line != 6 || len(matches[1].After) != 0 {
+		t.Errorf("Unexpected last match: %+v", matches[1])
+	}
+
+	matches, truncated = FindLineMatches(text, pattern, 0, 1)
+	if !truncated || len(matches) != 1 || matches[0].Before != nil {
+		t.Errorf("Expected 1 truncated match without context, got %+v (truncated %v)", matches, truncated)
+	}
+
+	matches, truncated = FindLineMatches("", pattern, 2, 10)
+	if truncated || len(matches) != 0 {
+		t.Errorf("Expected no matches, got %+v", matches)
+	}
+}
@@ -0,0 +1,114 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"path"
+	"regexp"
+	"time"
+)
+
+// SessionSelector picks sessions for bulk operations
+// Every criterion that is set must match; an empty selector matches nothing
+type SessionSelector struct {
+	Names     []string `json:"names,omitempty"`     // Exact session names
+	Group     string   `json:"group,omitempty"`     // Session group, as reported in the session list
+	Glob      string   `json:"glob,omitempty"`      // Shell-style name pattern, e.g. gt-rig-*
+	Regex     string   `json:"regex,omitempty"`     // Regular expression matched against the name
+	State     string   `json:"state,omitempty"`     // Agent state from the session monitor
+	OlderThan string   `json:"olderThan,omitempty"` // Created at least this long ago (Go duration, e.g. 2h)
+	IdleFor   string   `json:"idleFor,omitempty"`   // No activity for at least this long
+}
+
+// IsEmpty reports whether the selector has no criteria
+func (s SessionSelector) IsEmpty() bool {
+	return len(s.Names) == 0 && s.Group == "" && s.Glob == "" && s.Regex == "" &&
+		s.State == "" && s.OlderThan == "" && s.IdleFor == ""
+}
+
+// ValidateSelector validates a session selector
+func ValidateSelector(s SessionSelector) (bool, string) {
+	if s.IsEmpty() {
+		return false, "selector needs at least one of names, group, glob, regex, state, olderThan or idleFor."
+	}
+	if s.Glob != "" {
+		if _, err := path.Match(s.Glob, ""); err != nil {
+			return false, "Invalid glob: " + err.Error()
+		}
+	}
+	if s.Regex != "" {
+		if _, err := regexp.Compile(s.Regex); err != nil {
+			return false, "Invalid regex: " + err.Error()
+		}
+	}
+	switch s.State {
+	case "", StateWorking, StateIdle, StateWaiting, StateCrashed:
+	default:
+		return false, "Invalid state. Use working, idle, waiting or crashed."
+	}
+	for param, value := range map[string]string{"olderThan": s.OlderThan, "idleFor": s.IdleFor} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return false, "Invalid " + param + ". Use a duration like 30m or 2h."
+		}
+	}
+	return true, ""
+}
+
+// SelectSessions returns the sessions matching a validated selector, in their original order
+func SelectSessions(sessions []Session, s SessionSelector, now time.Time) []Session {
+	selected := []Session{}
+	if s.IsEmpty() {
+		return selected
+	}
+
+	names := make(map[string]bool, len(s.Names))
+	for _, name := range s.Names {
+		names[name] = true
+	}
+	var re *regexp.Regexp
+	if s.Regex != "" {
+		re = regexp.MustCompile(s.Regex)
+	}
+	olderThan, _ := time.ParseDuration(s.OlderThan)
+	idleFor, _ := time.ParseDuration(s.IdleFor)
+
+	for _, session := range sessions {
+		if len(names) > 0 && !names[session.Name] {
+			continue
+		}
+		if s.Group != "" && session.Group != s.Group {
+			continue
+		}
+		if s.Glob != "" {
+			if ok, _ := path.Match(s.Glob, session.Name); !ok {
+				continue
+			}
+		}
+		if re != nil && !re.MatchString(session.Name) {
+			continue
+		}
+		if s.State != "" && session.State != s.State {
+			continue
+		}
+		if s.OlderThan != "" && !olderThanAt(session.Created, olderThan, now) {
+			continue
+		}
+		if s.IdleFor != "" && !olderThanAt(session.LastActivity, idleFor, now) {
+			continue
+		}
+		selected = append(selected, session)
+	}
+	return selected
+}
+
+// olderThanAt reports whether an RFC3339 timestamp is at least d before now
+// Unknown timestamps never match, so a session without one is never selected by age
+func olderThanAt(timestamp string, d time.Duration, now time.Time) bool {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return false
+	}
+	return now.Sub(t) >= d
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector SessionSelector
+		valid    bool
+	}{
+		{"empty", SessionSelector{}, false},
+		{"group", SessionSelector{Group: "gt-rig"}, true},
+		{"glob", SessionSelector{Glob: "gt-rig-*"}, true},
+		{"bad glob", SessionSelector{Glob: "gt-[rig"}, false},
+		{"regex", SessionSelector{Regex: `^gt-rig-\d+$`}, true},
+		{"bad regex", SessionSelector{Regex: `(`}, false},
+		{"state", SessionSelector{State: StateIdle}, true},
+		{"bad state", SessionSelector{State: "sleeping"}, false},
+		{"age", SessionSelector{OlderThan: "2h", IdleFor: "30m"}, true},
+		{"bad age", SessionSelector{OlderThan: "two hours"}, false},
+		{"negative age", SessionSelector{IdleFor: "-5m"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, errMsg := ValidateSelector(tt.selector)
+			if valid != tt.valid {
+				t.Errorf("ValidateSelector() = %v (%s), expected %v", valid, errMsg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestSelectSessions(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []Session{
+		{Name: "gt-rig-1", Group: "gt-rig", State: StateIdle, Created: "2026-01-01T08:00:00Z", LastActivity: "2026-01-01T11:00:00Z"},
+		{Name: "gt-rig-2", Group: "gt-rig", State: StateWorking, Created: "2026-01-01T11:30:00Z", LastActivity: "2026-01-01T11:59:00Z"},
+		{Name: "gt-other-1", Group: "gt-other", State: StateIdle, Created: "2026-01-01T08:00:00Z"},
+		{Name: "main", Group: "main"},
+	}
+
+	names := func(selected []Session) []string {
+		out := []string{}
+		for _, s := range selected {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		selector SessionSelector
+		expected []string
+	}{
+		{"empty selects nothing", SessionSelector{}, []string{}},
+		{"names", SessionSelector{Names: []string{"main", "gt-rig-2"}}, []string{"gt-rig-2", "main"}},
+		{"group", SessionSelector{Group: "gt-rig"}, []string{"gt-rig-1", "gt-rig-2"}},
+		{"glob", SessionSelector{Glob: "gt-*-1"}, []string{"gt-rig-1", "gt-other-1"}},
+		{"regex", SessionSelector{Regex: `^gt-rig-[2-9]$`}, []string{"gt-rig-2"}},
+		{"state", SessionSelector{State: StateIdle}, []string{"gt-rig-1", "gt-other-1"}},
+		{"older than", SessionSelector{OlderThan: "2h"}, []string{"gt-rig-1", "gt-other-1"}},
+		{"idle for skips unknown activity", SessionSelector{IdleFor: "30m"}, []string{"gt-rig-1"}},
+		{"combined", SessionSelector{Group: "gt-rig", State: StateIdle}, []string{"gt-rig-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(SelectSessions(sessions, tt.selector, now))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("SelectSessions() = %v, expected %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("SelectSessions() = %v, expected %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
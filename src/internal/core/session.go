@@ -5,15 +5,100 @@ import (
 	"os"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Session represents a tmux session
 type Session struct {
-	Name     string `json:"name"`
-	Windows  int    `json:"windows"`
-	Attached bool   `json:"attached"`
-	Group    string `json:"group"`
+	ID           string `json:"id"` // tmux session ID ($N), stable across renames
+	Name         string `json:"name"`
+	Windows      int    `json:"windows"`
+	Attached     bool   `json:"attached"`
+	Clients      int    `json:"clients"` // Attached clients, control mode clients excluded
+	Group        string `json:"group"`
+	Created      string `json:"created,omitempty"`
+	LastActivity string `json:"lastActivity,omitempty"`
+	Path         string `json:"path,omitempty"`
+	Command      string `json:"command,omitempty"` // Foreground command of the active pane
+	PID          int    `json:"pid,omitempty"`     // PID of the active pane's process
+	State        string `json:"state,omitempty"`   // Agent state from the session monitor (see ClassifySession)
+	StateSince   string `json:"stateSince,omitempty"`
+	Protected    bool   `json:"protected"` // Refuses kills unless forced
+}
+
+// GroupOption is the tmux user option that pins a session to a group, overriding CategorizeSession
+const GroupOption = "@chrote-group"
+
+// ProtectedOption is the tmux user option that marks a session as protected from kills
+// Stored on the session itself so protection survives server restarts and renames
+const ProtectedOption = "@chrote-protected"
+
+// SessionFormat is the list-sessions format parsed by ParseSessions
+// Pane fields refer to the active pane of the session's current window
+const SessionFormat = "#{session_id}\t#{session_windows}\t#{session_created}\t#{session_activity}\t" +
+	"#{pane_pid}\t#{pane_current_command}\t#{session_path}\t#{" + GroupOption + "}\t#{" + ProtectedOption + "}\t" +
+	"#{session_name}"
+
+// sessionFormatFields is the number of fields in SessionFormat
+const sessionFormatFields = 10
+
+// Session sort orders accepted by SortSessionsBy
+const (
+	SortByGroup    = "group"
+	SortByActivity = "activity"
+	SortByCreated  = "created"
+	SortByName     = "name"
+)
+
+// ParseSessions parses list-sessions output in SessionFormat
+// clients maps session IDs to their attached (non-control) client count
+func ParseSessions(output string, clients map[string]int) []Session {
+	sessions := []Session{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		parts := strings.SplitN(line, "\t", sessionFormatFields)
+		if len(parts) != sessionFormatFields {
+			continue
+		}
+
+		windows, _ := strconv.Atoi(parts[1])
+		if windows == 0 {
+			windows = 1
+		}
+		pid, _ := strconv.Atoi(parts[4])
+		name := parts[9]
+		group := parts[7]
+		if group == "" {
+			group = CategorizeSession(name)
+		}
+
+		sessions = append(sessions, Session{
+			ID:           parts[0],
+			Name:         name,
+			Windows:      windows,
+			Attached:     clients[parts[0]] > 0,
+			Clients:      clients[parts[0]],
+			Group:        group,
+			Created:      formatUnixTime(parts[2]),
+			LastActivity: formatUnixTime(parts[3]),
+			Path:         parts[6],
+			Command:      parts[5],
+			PID:          pid,
+			Protected:    parts[8] == "1",
+		})
+	}
+	return sessions
+}
+
+// formatUnixTime converts a tmux unix timestamp to RFC3339, or "" if it isn't one
+func formatUnixTime(value string) string {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return ""
+	}
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
 }
 
 // GroupPriority defines the sort order for session groups
@@ -23,7 +108,11 @@ var GroupPriority = map[string]int{
 }
 
 // GetGroupPriority returns the sort priority for a group
+// Priorities from the grouping config take precedence over the built-in ones
 func GetGroupPriority(group string) int {
+	if p, ok := configuredGroupPriority(group); ok {
+		return p
+	}
 	if p, ok := GroupPriority[group]; ok {
 		return p
 	}
@@ -34,7 +123,14 @@ func GetGroupPriority(group string) int {
 }
 
 // CategorizeSession determines the group for a session based on its name
+// Configured grouping rules are tried first (see SetGroupingConfig); option rules
+// never match here, use CategorizeSessionWithOptions when option values are known
 func CategorizeSession(name string) string {
+	return CategorizeSessionWithOptions(name, nil)
+}
+
+// categorizeByConvention applies the built-in hq-, main/shell and gt- naming conventions
+func categorizeByConvention(name string) string {
 	if strings.HasPrefix(name, "hq-") {
 		return "hq"
 	}
@@ -66,7 +162,38 @@ func SortSessions(sessions []Session) {
 	})
 }
 
-// GroupSessions organizes sessions by group
+// IsValidSortOrder reports whether order is accepted by SortSessionsBy
+func IsValidSortOrder(order string) bool {
+	switch order {
+	case SortByGroup, SortByActivity, SortByCreated, SortByName:
+		return true
+	}
+	return false
+}
+
+// SortSessionsBy sorts sessions by the given order
+// activity and created put the most recent first; unknown orders fall back to SortSessions
+// Timestamps are UTC RFC3339 strings, which sort chronologically as text
+func SortSessionsBy(sessions []Session, order string) {
+	switch order {
+	case SortByActivity:
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].LastActivity > sessions[j].LastActivity
+		})
+	case SortByCreated:
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].Created > sessions[j].Created
+		})
+	case SortByName:
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].Name < sessions[j].Name
+		})
+	default:
+		SortSessions(sessions)
+	}
+}
+
+// GroupSessions organizes sessions by group, keeping their order within each group
 func GroupSessions(sessions []Session) map[string][]Session {
 	grouped := make(map[string][]Session)
 	for _, s := range sessions {
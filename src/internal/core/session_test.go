@@ -78,6 +78,79 @@ func TestSortSessions(t *testing.T) {
 	}
 }
 
+func TestSortSessionsBy(t *testing.T) {
+	sessions := []Session{
+		{Name: "b", Group: "other", Created: "2026-01-01T10:00:00Z", LastActivity: "2026-01-02T09:00:00Z"},
+		{Name: "a", Group: "hq", Created: "2026-01-01T12:00:00Z", LastActivity: "2026-01-02T08:00:00Z"},
+		{Name: "c", Group: "main", Created: "2026-01-01T11:00:00Z", LastActivity: "2026-01-02T10:00:00Z"},
+	}
+
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		{SortByActivity, []string{"c", "b", "a"}},
+		{SortByCreated, []string{"a", "c", "b"}},
+		{SortByName, []string{"a", "b", "c"}},
+		{SortByGroup, []string{"a", "c", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			sorted := append([]Session{}, sessions...)
+			SortSessionsBy(sorted, tt.order)
+			for i, name := range tt.expected {
+				if sorted[i].Name != name {
+					t.Errorf("Position %d: got %q, expected %q", i, sorted[i].Name, name)
+				}
+			}
+		})
+	}
+
+	if IsValidSortOrder("random") {
+		t.Error("IsValidSortOrder(\"random\") should be false")
+	}
+}
+
+func TestParseSessions(t *testing.T) {
+	output := "$1\t2\t1767261600\t1767348000\t4242\tclaude\t/code/proj\t\t1\thq-mayor\n" +
+		"$2\t0\tbad\t\t\t\t\t\t\tshell\n" +
+		"$3\t1\t1767261600\t1767261600\t99\tbash\t/code\tagents\t\tgt-x-1\n" +
+		"incomplete\tline\n"
+
+	sessions := ParseSessions(output, map[string]int{"$1": 2})
+
+	if len(sessions) != 3 {
+		t.Fatalf("Expected 3 sessions, got %d", len(sessions))
+	}
+
+	mayor := sessions[0]
+	if mayor.ID != "$1" || mayor.Name != "hq-mayor" || mayor.Group != "hq" || mayor.Windows != 2 {
+		t.Errorf("Unexpected session: %+v", mayor)
+	}
+	if !mayor.Attached || mayor.Clients != 2 {
+		t.Errorf("Expected 2 attached clients, got attached=%v clients=%d", mayor.Attached, mayor.Clients)
+	}
+	if mayor.Created != "2026-01-01T10:00:00Z" || mayor.LastActivity != "2026-01-02T10:00:00Z" {
+		t.Errorf("Unexpected timestamps: created=%q activity=%q", mayor.Created, mayor.LastActivity)
+	}
+	if mayor.Command != "claude" || mayor.PID != 4242 || mayor.Path != "/code/proj" {
+		t.Errorf("Unexpected pane metadata: %+v", mayor)
+	}
+	if !mayor.Protected {
+		t.Errorf("Expected %s to mark the session protected", ProtectedOption)
+	}
+
+	shell := sessions[1]
+	if shell.Windows != 1 || shell.Created != "" || shell.Attached || shell.Protected {
+		t.Errorf("Unexpected fallback values: %+v", shell)
+	}
+
+	if pinned := sessions[2]; pinned.Group != "agents" || pinned.Name != "gt-x-1" {
+		t.Errorf("Expected %s group to override the name, got %+v", GroupOption, pinned)
+	}
+}
+
 func TestGroupSessions(t *testing.T) {
 	sessions := []Session{
 		{Name: "hq-1", Group: "hq"},
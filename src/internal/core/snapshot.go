@@ -0,0 +1,120 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SnapshotsDir is the config subdirectory session snapshots are stored in
+const SnapshotsDir = "snapshots"
+
+// Snapshot records the layout of tmux sessions so they can be rebuilt later
+type Snapshot struct {
+	Name     string            `json:"name"`
+	Created  string            `json:"created"`
+	Sessions []SessionSnapshot `json:"sessions"`
+}
+
+// SessionSnapshot records one session
+type SessionSnapshot struct {
+	Name      string   `json:"name"`
+	Group     string   `json:"group,omitempty"` // Only set when pinned with @chrote-group
+	Protected bool     `json:"protected,omitempty"`
+	Windows   []Window `json:"windows"`
+	// Foreground command lines and scrollback per pane ID, captured best-effort
+	CommandLines map[string]string `json:"commandLines,omitempty"`
+	Scrollback   map[string]string `json:"scrollback,omitempty"`
+}
+
+// SnapshotInfo summarizes a stored snapshot
+type SnapshotInfo struct {
+	Name     string `json:"name"`
+	Created  string `json:"created"`
+	Sessions int    `json:"sessions"`
+	Size     int64  `json:"size"`
+}
+
+// ValidateSnapshotName validates a snapshot name
+func ValidateSnapshotName(name string) (bool, string) {
+	return ValidateSessionName(name, "snapshot name")
+}
+
+// SnapshotFile returns the config file name for a snapshot
+func SnapshotFile(name string) string {
+	return filepath.Join(SnapshotsDir, name+".json")
+}
+
+// ListSnapshots returns the stored snapshots, most recent first
+func ListSnapshots() ([]SnapshotInfo, error) {
+	snapshots := []SnapshotInfo{}
+
+	entries, err := os.ReadDir(filepath.Join(GetConfigDir(), SnapshotsDir))
+	if os.IsNotExist(err) {
+		return snapshots, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if valid, _ := ValidateSnapshotName(name); !valid {
+			continue
+		}
+		var snapshot Snapshot
+		if err := LoadConfigFile(SnapshotFile(name), &snapshot); err != nil {
+			continue
+		}
+		info := SnapshotInfo{Name: name, Created: snapshot.Created, Sessions: len(snapshot.Sessions)}
+		if fi, err := entry.Info(); err == nil {
+			info.Size = fi.Size()
+		}
+		snapshots = append(snapshots, info)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Created > snapshots[j].Created
+	})
+	return snapshots, nil
+}
+
+// ParseSnapshotPanes parses list-panes -a output where each line is the session
+// name, @chrote-group, @chrote-protected and then the PaneFormat fields
+// Sessions are returned in the order tmux listed them
+func ParseSnapshotPanes(output string) []SessionSnapshot {
+	var order []string
+	lines := make(map[string][]string)
+	sessions := make(map[string]*SessionSnapshot)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		name := parts[0]
+		if _, ok := sessions[name]; !ok {
+			order = append(order, name)
+			sessions[name] = &SessionSnapshot{
+				Name:      name,
+				Group:     parts[1],
+				Protected: parts[2] == "1",
+			}
+		}
+		lines[name] = append(lines[name], parts[3])
+	}
+
+	result := make([]SessionSnapshot, 0, len(order))
+	for _, name := range order {
+		session := sessions[name]
+		session.Windows = ParsePanes(strings.Join(lines[name], "\n"))
+		result = append(result, *session)
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package core
+
+import "testing"
+
+func TestParseSnapshotPanes(t *testing.T) {
+	output := "hq-mayor\t\t1\t0\t@1\t1\teven-horizontal\tmain\t0\t%1\t100\t1\t0\t40\t24\tclaude\t/code/hq\n" +
+		"hq-mayor\t\t1\t0\t@1\t1\teven-horizontal\tmain\t1\t%2\t101\t0\t0\t39\t24\tbash\t/code/hq/logs\n" +
+		"web\tfrontend\t\t1\t@2\t1\ttiled\tdev\t0\t%3\t200\t1\t0\t80\t24\tnpm\t/code/web\n" +
+		"short\tline\n"
+
+	sessions := ParseSnapshotPanes(output)
+
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+
+	mayor := sessions[0]
+	if mayor.Name != "hq-mayor" || !mayor.Protected || mayor.Group != "" {
+		t.Errorf("Unexpected session: %+v", mayor)
+	}
+	if len(mayor.Windows) != 1 || len(mayor.Windows[0].Panes) != 2 || mayor.Windows[0].Layout != "even-horizontal" {
+		t.Fatalf("Unexpected windows: %+v", mayor.Windows)
+	}
+	if p := mayor.Windows[0].Panes[1]; p.Path != "/code/hq/logs" || p.Command != "bash" {
+		t.Errorf("Unexpected pane: %+v", p)
+	}
+
+	web := sessions[1]
+	if web.Group != "frontend" || web.Protected || web.Windows[0].Index != 1 {
+		t.Errorf("Unexpected session: %+v", web)
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	t.Setenv("CHROTE_CONFIG_DIR", t.TempDir())
+
+	if snapshots, err := ListSnapshots(); err != nil || len(snapshots) != 0 {
+		t.Fatalf("Expected no snapshots, got %v, %v", snapshots, err)
+	}
+
+	SaveConfigFile(SnapshotFile("older"), Snapshot{Name: "older", Created: "2026-01-01T00:00:00Z"})
+	SaveConfigFile(SnapshotFile("newer"), Snapshot{Name: "newer", Created: "2026-02-01T00:00:00Z", Sessions: []SessionSnapshot{{Name: "a"}}})
+
+	snapshots, err := ListSnapshots()
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != "newer" || snapshots[0].Sessions != 1 || snapshots[0].Size == 0 {
+		t.Errorf("Unexpected snapshots: %+v", snapshots)
+	}
+}
@@ -0,0 +1,37 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"regexp"
+)
+
+// IndexRegex validates tmux window and pane indexes
+var IndexRegex = regexp.MustCompile(`^[0-9]{1,4}$`)
+
+// BuildTarget builds an exact-match tmux target (=session:window.pane)
+// Window and pane are optional; an empty pane targets the window's active pane
+func BuildTarget(session, window, pane string) (string, string) {
+	if valid, errMsg := ValidateSessionName(session, "session name"); !valid {
+		return "", errMsg
+	}
+
+	target := "=" + session + ":"
+	if window == "" {
+		if pane != "" {
+			return "", "window is required when pane is given."
+		}
+		return target, ""
+	}
+	if !IndexRegex.MatchString(window) {
+		return "", "Invalid window index. Use a non-negative number."
+	}
+	target += window
+
+	if pane != "" {
+		if !IndexRegex.MatchString(pane) {
+			return "", "Invalid pane index. Use a non-negative number."
+		}
+		target += "." + pane
+	}
+	return target, ""
+}
@@ -0,0 +1,36 @@
+package core
+
+import "testing"
+
+func TestBuildTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		session  string
+		window   string
+		pane     string
+		expected string
+		valid    bool
+	}{
+		{"session only", "main", "", "", "=main:", true},
+		{"session and window", "main", "1", "", "=main:1", true},
+		{"full target", "gt-rig-jack", "2", "0", "=gt-rig-jack:2.0", true},
+		{"invalid session", "bad name", "", "", "", false},
+		{"empty session", "", "", "", "", false},
+		{"pane without window", "main", "", "1", "", false},
+		{"non-numeric window", "main", "editor", "", "", false},
+		{"negative pane", "main", "0", "-1", "", false},
+		{"injection attempt", "main", "0;kill-server", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, errMsg := BuildTarget(tt.session, tt.window, tt.pane)
+			if (errMsg == "") != tt.valid {
+				t.Errorf("BuildTarget(%q, %q, %q) errMsg = %q, expected valid = %v", tt.session, tt.window, tt.pane, errMsg, tt.valid)
+			}
+			if target != tt.expected {
+				t.Errorf("BuildTarget(%q, %q, %q) = %q, expected %q", tt.session, tt.window, tt.pane, target, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"regexp"
+	"sort"
+)
+
+// TemplatesFile is the config file session templates are stored in
+const TemplatesFile = "templates.json"
+
+// maxTemplateWindows bounds how many windows a template may create
+const maxTemplateWindows = 20
+
+// maxTemplatePanes bounds how many extra panes a template window may split off
+const maxTemplatePanes = 8
+
+// SessionTemplate is a named launch profile for new sessions
+// String fields may reference {{variables}} supplied when the session is created;
+// {{session}} is always the new session's name
+type SessionTemplate struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Cwd         string            `json:"cwd,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Group       string            `json:"group,omitempty"`
+	Windows     []TemplateWindow  `json:"windows,omitempty"` // The first entry is the session's initial window
+}
+
+// TemplateWindow describes one window of a session template
+type TemplateWindow struct {
+	Name     string         `json:"name,omitempty"`
+	Cwd      string         `json:"cwd,omitempty"`      // Defaults to the template's cwd
+	Commands []string       `json:"commands,omitempty"` // Typed into the first pane, each followed by Enter
+	Panes    []TemplatePane `json:"panes,omitempty"`    // Extra panes split off the first
+	Layout   string         `json:"layout,omitempty"`   // Applied once all panes exist
+}
+
+// TemplatePane describes an extra pane of a template window
+type TemplatePane struct {
+	Cwd      string   `json:"cwd,omitempty"`
+	Commands []string `json:"commands,omitempty"`
+}
+
+// TemplateLayouts are the tmux preset layouts a template window may use
+var TemplateLayouts = map[string]bool{
+	"even-horizontal": true,
+	"even-vertical":   true,
+	"main-horizontal": true,
+	"main-vertical":   true,
+	"tiled":           true,
+}
+
+// TemplateVarRegex matches {{variable}} references in template strings
+var TemplateVarRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// EnvNameRegex validates environment variable names
+var EnvNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ValidateTemplate checks a template's structure
+// Values that may contain variables (paths, commands) are checked after ApplyTemplateVars
+func ValidateTemplate(t SessionTemplate) (bool, string) {
+	if valid, errMsg := ValidateSessionName(t.Name, "template name"); !valid {
+		return false, errMsg
+	}
+	if t.Group != "" && !TemplateVarRegex.MatchString(t.Group) {
+		if valid, errMsg := ValidateSessionName(t.Group, "group"); !valid {
+			return false, errMsg
+		}
+	}
+	for key := range t.Env {
+		if !EnvNameRegex.MatchString(key) {
+			return false, "Invalid environment variable name: " + key
+		}
+	}
+	if len(t.Windows) > maxTemplateWindows {
+		return false, "too many windows (max 20)."
+	}
+	for _, w := range t.Windows {
+		if w.Name != "" && !TemplateVarRegex.MatchString(w.Name) {
+			if valid, errMsg := ValidateWindowName(w.Name); !valid {
+				return false, errMsg
+			}
+		}
+		if w.Layout != "" && !TemplateLayouts[w.Layout] {
+			return false, "Invalid layout. Use even-horizontal, even-vertical, main-horizontal, main-vertical or tiled."
+		}
+		if len(w.Panes) > maxTemplatePanes {
+			return false, "too many panes in one window (max 8)."
+		}
+	}
+	return true, ""
+}
+
+// TemplateVars returns the variables referenced by a template, sorted
+func TemplateVars(t SessionTemplate) []string {
+	seen := make(map[string]bool)
+	forEachTemplateString(&t, func(s *string) {
+		for _, match := range TemplateVarRegex.FindAllStringSubmatch(*s, -1) {
+			seen[match[1]] = true
+		}
+	})
+
+	vars := make([]string, 0, len(seen))
+	for name := range seen {
+		vars = append(vars, name)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// ApplyTemplateVars returns a copy of the template with every {{variable}} replaced
+// Returns the names of referenced variables that have no value, sorted
+func ApplyTemplateVars(t SessionTemplate, vars map[string]string) (SessionTemplate, []string) {
+	resolved := t
+	resolved.Env = make(map[string]string, len(t.Env))
+	for key, value := range t.Env {
+		resolved.Env[key] = value
+	}
+	resolved.Windows = make([]TemplateWindow, len(t.Windows))
+	for i, w := range t.Windows {
+		resolved.Windows[i] = w
+		resolved.Windows[i].Commands = append([]string{}, w.Commands...)
+		resolved.Windows[i].Panes = make([]TemplatePane, len(w.Panes))
+		for j, p := range w.Panes {
+			resolved.Windows[i].Panes[j] = p
+			resolved.Windows[i].Panes[j].Commands = append([]string{}, p.Commands...)
+		}
+	}
+
+	missing := make(map[string]bool)
+	forEachTemplateString(&resolved, func(s *string) {
+		*s = TemplateVarRegex.ReplaceAllStringFunc(*s, func(ref string) string {
+			name := TemplateVarRegex.FindStringSubmatch(ref)[1]
+			value, ok := vars[name]
+			if !ok {
+				missing[name] = true
+				return ref
+			}
+			return value
+		})
+	})
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return resolved, names
+}
+
+// forEachTemplateString calls fn with every string field of t that may contain variables
+func forEachTemplateString(t *SessionTemplate, fn func(*string)) {
+	fn(&t.Cwd)
+	fn(&t.Group)
+	for key, value := range t.Env {
+		if fn(&value); value != t.Env[key] {
+			t.Env[key] = value
+		}
+	}
+	for i := range t.Windows {
+		w := &t.Windows[i]
+		fn(&w.Name)
+		fn(&w.Cwd)
+		for j := range w.Commands {
+			fn(&w.Commands[j])
+		}
+		for j := range w.Panes {
+			p := &w.Panes[j]
+			fn(&p.Cwd)
+			for k := range p.Commands {
+				fn(&p.Commands[k])
+			}
+		}
+	}
+}
+
+// FindTemplate returns the index of the named template, or -1
+func FindTemplate(templates []SessionTemplate, name string) int {
+	for i, t := range templates {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testTemplate() SessionTemplate {
+	return SessionTemplate{
+		Name:  "agent",
+		Cwd:   "/code/{{project}}",
+		Env:   map[string]string{"BRANCH": "{{ branch }}"},
+		Group: "agents",
+		Windows: []TemplateWindow{
+			{Name: "claude", Commands: []string{"git checkout {{branch}}", "claude"}},
+			{Name: "logs", Panes: []TemplatePane{{Commands: []string{"tail -f {{session}}.log"}}}, Layout: "tiled"},
+		},
+	}
+}
+
+func TestTemplateVars(t *testing.T) {
+	vars := TemplateVars(testTemplate())
+	expected := []string{"branch", "project", "session"}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("TemplateVars() = %v, expected %v", vars, expected)
+	}
+}
+
+func TestApplyTemplateVars(t *testing.T) {
+	original := testTemplate()
+	resolved, missing := ApplyTemplateVars(original, map[string]string{
+		"project": "chrote",
+		"branch":  "main",
+		"session": "agent-1",
+	})
+
+	if len(missing) != 0 {
+		t.Fatalf("Unexpected missing vars: %v", missing)
+	}
+	if resolved.Cwd != "/code/chrote" || resolved.Env["BRANCH"] != "main" {
+		t.Errorf("Unexpected resolved template: %+v", resolved)
+	}
+	if resolved.Windows[0].Commands[0] != "git checkout main" {
+		t.Errorf("Unexpected window command: %q", resolved.Windows[0].Commands[0])
+	}
+	if resolved.Windows[1].Panes[0].Commands[0] != "tail -f agent-1.log" {
+		t.Errorf("Unexpected pane command: %q", resolved.Windows[1].Panes[0].Commands[0])
+	}
+
+	// The stored template must not be modified
+	if !reflect.DeepEqual(original, testTemplate()) {
+		t.Errorf("ApplyTemplateVars modified its input: %+v", original)
+	}
+}
+
+func TestApplyTemplateVars_Missing(t *testing.T) {
+	resolved, missing := ApplyTemplateVars(testTemplate(), map[string]string{"project": "x"})
+
+	expected := []string{"branch", "session"}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Errorf("missing = %v, expected %v", missing, expected)
+	}
+	if resolved.Env["BRANCH"] != "{{ branch }}" {
+		t.Errorf("Unresolved references should be left in place, got %q", resolved.Env["BRANCH"])
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*SessionTemplate)
+		valid  bool
+	}{
+		{"valid", func(t *SessionTemplate) {}, true},
+		{"missing name", func(t *SessionTemplate) { t.Name = "" }, false},
+		{"bad name", func(t *SessionTemplate) { t.Name = "a b" }, false},
+		{"bad group", func(t *SessionTemplate) { t.Group = "a;b" }, false},
+		{"group variable", func(t *SessionTemplate) { t.Group = "{{team}}" }, true},
+		{"bad env name", func(t *SessionTemplate) { t.Env["1BAD"] = "x" }, false},
+		{"bad layout", func(t *SessionTemplate) { t.Windows[0].Layout = "spiral" }, false},
+		{"bad window name", func(t *SessionTemplate) { t.Windows[0].Name = "a;b" }, false},
+		{"too many windows", func(t *SessionTemplate) { t.Windows = make([]TemplateWindow, 21) }, false},
+		{"too many panes", func(t *SessionTemplate) { t.Windows[1].Panes = make([]TemplatePane, 9) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := testTemplate()
+			tt.modify(&template)
+			valid, errMsg := ValidateTemplate(template)
+			if valid != tt.valid {
+				t.Errorf("ValidateTemplate() = %v (%s), expected %v", valid, errMsg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestFindTemplate(t *testing.T) {
+	templates := []SessionTemplate{{Name: "a"}, {Name: "b"}}
+	if FindTemplate(templates, "b") != 1 || FindTemplate(templates, "c") != -1 {
+		t.Error("FindTemplate returned the wrong index")
+	}
+}
@@ -0,0 +1,181 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WatchersFile is the config file output watchers are stored in
+const WatchersFile = "watchers.json"
+
+// MaxWatchers bounds the number of configured watchers
+const MaxWatchers = 100
+
+// DefaultWatchCooldown is how long a watcher waits before firing again on the same pane
+const DefaultWatchCooldown = 30 * time.Second
+
+// Watcher actions
+const (
+	WatchActionEvent    = "event"     // Only publish a watcher-fired event (every action does this too)
+	WatchActionSendKeys = "send-keys" // Type into the pane that matched
+	WatchActionNudge    = "nudge"     // Run gt nudge in a Gastown workspace
+	WatchActionKill     = "kill"      // Kill the session (never protected ones)
+	WatchActionBead     = "bead"      // Create a beads issue in a project
+)
+
+// Watcher fires an action when new output of a selected session matches a pattern
+// Message and Title may reference {{session}}, {{window}}, {{pane}} and {{match}}
+type Watcher struct {
+	Name     string          `json:"name"`
+	Selector SessionSelector `json:"selector"`
+	Pattern  string          `json:"pattern"`
+	Action   string          `json:"action"`
+	Cooldown string          `json:"cooldown,omitempty"` // Go duration, defaults to 30s
+	Disabled bool            `json:"disabled,omitempty"`
+
+	// send-keys
+	Text  string   `json:"text,omitempty"`
+	Keys  []string `json:"keys,omitempty"`
+	Enter bool     `json:"enter,omitempty"`
+
+	// nudge: gt nudge <target> -m <message>, run in workspace
+	Workspace string `json:"workspace,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Message   string `json:"message,omitempty"`
+
+	// bead: issue created in project with the matched output as its description
+	Project string `json:"project,omitempty"`
+	Title   string `json:"title,omitempty"`
+}
+
+// ValidateWatcher checks a watcher's selector, pattern and action parameters
+// Workspace and project paths are checked against the filesystem by the caller
+func ValidateWatcher(w Watcher) (bool, string) {
+	if valid, errMsg := ValidateSessionName(w.Name, "watcher name"); !valid {
+		return false, errMsg
+	}
+	if valid, errMsg := ValidateSelector(w.Selector); !valid {
+		return false, errMsg
+	}
+	if w.Pattern == "" {
+		return false, "pattern is required."
+	}
+	if _, err := regexp.Compile(w.Pattern); err != nil {
+		return false, "Invalid pattern: " + err.Error()
+	}
+	if w.Cooldown != "" {
+		if d, err := time.ParseDuration(w.Cooldown); err != nil || d < time.Second {
+			return false, "Invalid cooldown. Use a duration of at least 1s, like 30s or 5m."
+		}
+	}
+
+	switch w.Action {
+	case WatchActionEvent, WatchActionKill:
+	case WatchActionSendKeys:
+		if w.Text == "" && len(w.Keys) == 0 && !w.Enter {
+			return false, "send-keys needs text, keys or enter."
+		}
+		if w.Text != "" {
+			if valid, errMsg := ValidateInputText(w.Text, "text"); !valid {
+				return false, errMsg
+			}
+		}
+		for _, key := range w.Keys {
+			if valid, errMsg := ValidateKeyName(key); !valid {
+				return false, errMsg
+			}
+		}
+	case WatchActionNudge:
+		if w.Workspace == "" || w.Target == "" {
+			return false, "nudge needs workspace and target."
+		}
+	case WatchActionBead:
+		if w.Project == "" {
+			return false, "bead needs project."
+		}
+	case "":
+		return false, "action is required."
+	default:
+		return false, "Invalid action. Use event, send-keys, nudge, kill or bead."
+	}
+	return true, ""
+}
+
+// WatchCooldown returns a validated watcher's cooldown
+func WatchCooldown(w Watcher) time.Duration {
+	if d, err := time.ParseDuration(w.Cooldown); err == nil {
+		return d
+	}
+	return DefaultWatchCooldown
+}
+
+// FindWatcher returns the index of the named watcher, or -1
+func FindWatcher(watchers []Watcher, name string) int {
+	for i, w := range watchers {
+		if w.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ExpandWatchVars replaces {{name}} references in s with values from vars
+// Unknown references are left as they are
+func ExpandWatchVars(s string, vars map[string]string) string {
+	return TemplateVarRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		name := TemplateVarRegex.FindStringSubmatch(ref)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// NewOutputLines returns the lines of a pane capture that were not in the previous capture
+// If the output scrolled, the previous lines are found at the top of the new capture
+// and everything below them is new; otherwise (a redraw or clear) every line that
+// changed in place is new. Blank lines are never reported
+func NewOutputLines(prev, cur []string) []string {
+	prev = trimBlankTail(prev)
+	cur = trimBlankTail(cur)
+
+	for d := 0; d < len(prev); d++ {
+		overlap := prev[d:]
+		if len(overlap) <= len(cur) && slices.Equal(overlap, cur[:len(overlap)]) {
+			return nonBlank(cur[len(overlap):])
+		}
+	}
+	if len(prev) == 0 {
+		return nonBlank(cur)
+	}
+
+	var fresh []string
+	for i, line := range cur {
+		if i >= len(prev) || line != prev[i] {
+			fresh = append(fresh, line)
+		}
+	}
+	return nonBlank(fresh)
+}
+
+// trimBlankTail drops trailing blank lines, the unused part of a pane
+func trimBlankTail(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// nonBlank returns the lines that are not blank
+func nonBlank(lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateWatcher(t *testing.T) {
+	base := func() Watcher {
+		return Watcher{
+			Name:     "proceed",
+			Selector: SessionSelector{Glob: "gt-*"},
+			Pattern:  `Proceed\? \(y/n\)`,
+			Action:   WatchActionSendKeys,
+			Text:     "y",
+			Enter:    true,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(w *Watcher)
+		valid  bool
+	}{
+		{"valid send-keys", func(w *Watcher) {}, true},
+		{"valid event", func(w *Watcher) { w.Action = WatchActionEvent }, true},
+		{"valid cooldown", func(w *Watcher) { w.Cooldown = "5m" }, true},
+		{"invalid name", func(w *Watcher) { w.Name = "bad name!" }, false},
+		{"empty selector", func(w *Watcher) { w.Selector = SessionSelector{} }, false},
+		{"missing pattern", func(w *Watcher) { w.Pattern = "" }, false},
+		{"invalid pattern", func(w *Watcher) { w.Pattern = "(" }, false},
+		{"cooldown too short", func(w *Watcher) { w.Cooldown = "100ms" }, false},
+		{"invalid cooldown", func(w *Watcher) { w.Cooldown = "soon" }, false},
+		{"send-keys without input", func(w *Watcher) { w.Text = ""; w.Enter = false }, false},
+		{"invalid key", func(w *Watcher) { w.Keys = []string{"NotAKey;"} }, false},
+		{"nudge without target", func(w *Watcher) { w.Action = WatchActionNudge; w.Workspace = "/gt" }, false},
+		{"nudge", func(w *Watcher) { w.Action = WatchActionNudge; w.Workspace = "/gt"; w.Target = "rig/crew" }, true},
+		{"bead without project", func(w *Watcher) { w.Action = WatchActionBead }, false},
+		{"missing action", func(w *Watcher) { w.Action = "" }, false},
+		{"unknown action", func(w *Watcher) { w.Action = "reboot" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := base()
+			tt.modify(&w)
+			if valid, errMsg := ValidateWatcher(w); valid != tt.valid {
+				t.Errorf("ValidateWatcher() = %v (%s), expected %v", valid, errMsg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestWatchCooldown(t *testing.T) {
+	if d := WatchCooldown(Watcher{}); d != DefaultWatchCooldown {
+		t.Errorf("Expected default cooldown, got %v", d)
+	}
+	if d := WatchCooldown(Watcher{Cooldown: "2m"}); d != 2*time.Minute {
+		t.Errorf("Expected 2m, got %v", d)
+	}
+}
+
+func TestExpandWatchVars(t *testing.T) {
+	got := ExpandWatchVars("{{session}} hit {{ match }} in {{unknown}}", map[string]string{
+		"session": "gt-rig-a",
+		"match":   "merge conflict",
+	})
+	if got != "gt-rig-a hit merge conflict in {{unknown}}" {
+		t.Errorf("Unexpected expansion: %q", got)
+	}
+}
+
+func TestNewOutputLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		prev     []string
+		cur      []string
+		expected []string
+	}{
+		{"first capture", nil, []string{"a", "b", ""}, []string{"a", "b"}},
+		{"unchanged", []string{"a", "b", "", ""}, []string{"a", "b", "", ""}, nil},
+		{"appended", []string{"a", "b", ""}, []string{"a", "b", "c", "d"}, []string{"c", "d"}},
+		{"scrolled", []string{"a", "b", "c"}, []string{"b", "c", "d", "e"}, []string{"d", "e"}},
+		{"redrawn in place", []string{"x", "Proceed? (y/n)", "z"}, []string{"x", "Done", "z"}, []string{"Done"}},
+		{"prompt line completed", []string{"out", "$ "}, []string{"out", "$ make"}, []string{"$ make"}},
+		{"cleared", []string{"a", "b"}, []string{"", ""}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewOutputLines(tt.prev, tt.cur)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("NewOutputLines() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindWatcher(t *testing.T) {
+	watchers := []Watcher{{Name: "a"}, {Name: "b"}}
+	if FindWatcher(watchers, "b") != 1 || FindWatcher(watchers, "c") != -1 {
+		t.Error("FindWatcher returned the wrong index")
+	}
+}
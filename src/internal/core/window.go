@@ -0,0 +1,107 @@
+// Package core provides business logic and utility functions
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Window represents a tmux window and its panes
+type Window struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	Layout string `json:"layout"`
+	Panes  []Pane `json:"panes"`
+}
+
+// Pane represents a tmux pane
+type Pane struct {
+	Index   int    `json:"index"`
+	ID      string `json:"id"`
+	PID     int    `json:"pid"`
+	Command string `json:"command"`
+	Path    string `json:"path"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Active  bool   `json:"active"`
+	Dead    bool   `json:"dead"`
+}
+
+// PaneFormat is the list-panes format parsed by ParsePanes
+// Fields are tab separated because window names and paths may contain other punctuation
+const PaneFormat = "#{window_index}\t#{window_id}\t#{window_active}\t#{window_layout}\t#{window_name}\t" +
+	"#{pane_index}\t#{pane_id}\t#{pane_pid}\t#{pane_active}\t#{pane_dead}\t#{pane_width}\t#{pane_height}\t" +
+	"#{pane_current_command}\t#{pane_current_path}"
+
+// paneFormatFields is the number of fields in PaneFormat
+const paneFormatFields = 14
+
+// WindowNameRegex validates window names
+var WindowNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_. -]+$`)
+
+// ValidateWindowName validates a window name
+func ValidateWindowName(name string) (bool, string) {
+	if name == "" {
+		return false, "window name is required."
+	}
+	if !WindowNameRegex.MatchString(name) {
+		return false, "Invalid window name. Use only letters, numbers, spaces, dots, dashes, and underscores."
+	}
+	if len(name) > 50 {
+		return false, "window name too long (max 50 characters)."
+	}
+	return true, ""
+}
+
+// ParsePanes parses list-panes output in PaneFormat into windows, in the order tmux listed them
+func ParsePanes(output string) []Window {
+	windows := []Window{}
+	byIndex := make(map[int]int)
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		parts := strings.SplitN(line, "\t", paneFormatFields)
+		if len(parts) != paneFormatFields {
+			continue
+		}
+
+		windowIndex, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		pos, ok := byIndex[windowIndex]
+		if !ok {
+			windows = append(windows, Window{
+				Index:  windowIndex,
+				ID:     parts[1],
+				Active: parts[2] == "1",
+				Layout: parts[3],
+				Name:   parts[4],
+				Panes:  []Pane{},
+			})
+			pos = len(windows) - 1
+			byIndex[windowIndex] = pos
+		}
+
+		paneIndex, _ := strconv.Atoi(parts[5])
+		pid, _ := strconv.Atoi(parts[7])
+		width, _ := strconv.Atoi(parts[10])
+		height, _ := strconv.Atoi(parts[11])
+		windows[pos].Panes = append(windows[pos].Panes, Pane{
+			Index:   paneIndex,
+			ID:      parts[6],
+			PID:     pid,
+			Active:  parts[8] == "1",
+			Dead:    parts[9] == "1",
+			Width:   width,
+			Height:  height,
+			Command: parts[12],
+			Path:    parts[13],
+		})
+	}
+
+	return windows
+}
@@ -0,0 +1,63 @@
+package core
+
+import "testing"
+
+func TestParsePanes(t *testing.T) {
+	output := "0\t@1\t1\tb25d,80x24,0,0{40x24,0,0,1,39x24,41,0,2}\tmy win\t0\t%1\t1234\t0\t0\t40\t24\tclaude\t/code/project\n" +
+		"0\t@1\t1\tb25d,80x24,0,0{40x24,0,0,1,39x24,41,0,2}\tmy win\t1\t%2\t1240\t1\t0\t39\t24\tbash\t/code/project\n" +
+		"2\t@3\t0\t5960,80x24,0,0,3\thelper\t0\t%3\t1300\t1\t1\t80\t24\tnode\t/code/path with\ttab\n" +
+		"garbage line\n"
+
+	windows := ParsePanes(output)
+
+	if len(windows) != 2 {
+		t.Fatalf("Expected 2 windows, got %d", len(windows))
+	}
+
+	first := windows[0]
+	if first.Index != 0 || first.Name != "my win" || !first.Active || len(first.Panes) != 2 {
+		t.Errorf("Unexpected first window: %+v", first)
+	}
+	if p := first.Panes[0]; p.PID != 1234 || p.Command != "claude" || p.Path != "/code/project" || p.Width != 40 || p.Active {
+		t.Errorf("Unexpected first pane: %+v", p)
+	}
+	if !first.Panes[1].Active {
+		t.Error("Second pane should be active")
+	}
+
+	second := windows[1]
+	if second.Index != 2 || second.Name != "helper" || second.Active {
+		t.Errorf("Unexpected second window: %+v", second)
+	}
+	if p := second.Panes[0]; !p.Dead || p.Path != "/code/path with\ttab" {
+		t.Errorf("Unexpected helper pane: %+v", p)
+	}
+}
+
+func TestParsePanes_Empty(t *testing.T) {
+	windows := ParsePanes("")
+	if windows == nil || len(windows) != 0 {
+		t.Errorf("ParsePanes(\"\") = %v, expected empty slice", windows)
+	}
+}
+
+func TestValidateWindowName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"editor", true},
+		{"my window", true},
+		{"v1.2_build-3", true},
+		{"", false},
+		{"bad;name", false},
+		{"aaaaaaaaaabbbbbbbbbbccccccccccddddddddddeeeeeeeeee1", false},
+	}
+
+	for _, tt := range tests {
+		valid, _ := ValidateWindowName(tt.name)
+		if valid != tt.valid {
+			t.Errorf("ValidateWindowName(%q) = %v, expected %v", tt.name, valid, tt.valid)
+		}
+	}
+}
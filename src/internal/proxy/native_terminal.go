@@ -0,0 +1,382 @@
+// Package proxy provides reverse proxy functionality for ttyd
+package proxy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrote/server/internal/core"
+	"github.com/gorilla/websocket"
+)
+
+// ttyd protocol message types (the first byte of every WebSocket message)
+const (
+	// client -> server
+	ttydInput  = '0'
+	ttydResize = '1'
+	ttydPause  = '2'
+	ttydResume = '3'
+	ttydJSON   = '{' // initial handshake: {"AuthToken":"","columns":80,"rows":24}
+
+	// server -> client
+	ttydOutput         = '0'
+	ttydSetWindowTitle = '1'
+	ttydSetPreferences = '2'
+)
+
+const (
+	// nativeHandshakeTimeout bounds the wait for the client's initial terminal size
+	nativeHandshakeTimeout = 10 * time.Second
+	// nativeExitTimeout is how long a client's process gets to exit after its pty closes
+	nativeExitTimeout = 2 * time.Second
+	// nativeReadBufferSize is the most pty output sent in one message
+	nativeReadBufferSize = 16 * 1024
+)
+
+// nativeTerminalPage is the built-in ttyd-compatible client page served when
+// CHROTE_TERMINAL_INDEX does not point to one (e.g. ttyd's html/dist/inline.html)
+//
+//go:embed native_terminal.html
+var nativeTerminalPage []byte
+
+// ttydHandshake is the first message a ttyd client sends
+type ttydHandshake struct {
+	AuthToken string `json:"AuthToken"`
+	Columns   uint16 `json:"columns"`
+	Rows      uint16 `json:"rows"`
+}
+
+// ttydResizeMessage is the payload of a resize message
+type ttydResizeMessage struct {
+	Columns uint16 `json:"columns"`
+	Rows    uint16 `json:"rows"`
+}
+
+// NativeTerminal serves terminals from in-process ptys instead of a ttyd child process
+// It speaks the ttyd WebSocket protocol, so ttyd's client page (and the dashboard
+// iframes using it) work unchanged. Every WebSocket gets its own pty attached to
+// the tmux session named by ?arg=, so any number of clients can view one session
+type NativeTerminal struct {
+	mu      sync.Mutex
+	clients map[*nativeClient]struct{}
+	stopped bool
+
+	// command builds the process for a client's ?arg= (terminalCommand; replaced in tests)
+	command func(arg string) *exec.Cmd
+}
+
+// nativeClient is one WebSocket connection and the process behind it
+type nativeClient struct {
+	conn *websocket.Conn
+	pty  *os.File
+	cmd  *exec.Cmd
+
+	writeMu sync.Mutex // serializes WebSocket writes
+
+	pauseMu sync.Mutex
+	paused  bool
+	resume  chan struct{} // closed when output resumes
+}
+
+// NewNativeTerminal creates a new NativeTerminal
+func NewNativeTerminal() *NativeTerminal {
+	return &NativeTerminal{
+		clients: make(map[*nativeClient]struct{}),
+		command: terminalCommand,
+	}
+}
+
+// IsRunning returns whether the backend accepts connections
+func (nt *NativeTerminal) IsRunning() bool {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+	return !nt.stopped
+}
+
+// ClientCount returns the number of connected terminals
+func (nt *NativeTerminal) ClientCount() int {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+	return len(nt.clients)
+}
+
+// Stop disconnects every client and ends their processes
+func (nt *NativeTerminal) Stop() error {
+	nt.mu.Lock()
+	nt.stopped = true
+	clients := make([]*nativeClient, 0, len(nt.clients))
+	for c := range nt.clients {
+		clients = append(clients, c)
+	}
+	nt.mu.Unlock()
+
+	for _, c := range clients {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// Handler returns an http.Handler serving the terminal page and its WebSocket
+func (nt *NativeTerminal) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/terminal")
+
+		switch {
+		case path == "/ws":
+			nt.serveWebSocket(w, r)
+		case path == "/token":
+			// ttyd clients fetch this before connecting; auth is handled by the server middleware
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"token": ""}`))
+		case path == "/" || path == "" || path == "/index.html":
+			nt.serveIndex(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// RegisterRoutes registers the terminal route
+func (nt *NativeTerminal) RegisterRoutes(mux *http.ServeMux) {
+	mux.Handle("/terminal/", nt.Handler())
+}
+
+// serveIndex serves the client page
+func (nt *NativeTerminal) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if index := os.Getenv("CHROTE_TERMINAL_INDEX"); index != "" {
+		http.ServeFile(w, r, index)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(nativeTerminalPage)
+}
+
+// terminalCommand returns the command a client runs: an attach to the named
+// tmux session if it exists, otherwise a login shell (like terminal-launch.sh)
+func terminalCommand(arg string) *exec.Cmd {
+	// When the server itself runs inside tmux, attach to that server by its socket:
+	// tmux refuses to attach with TMUX set
+	env := []string{"TERM=xterm-256color"}
+	var socket string
+	for _, e := range core.GetTmuxEnv() {
+		if value, ok := strings.CutPrefix(e, "TMUX="); ok {
+			socket, _, _ = strings.Cut(value, ",")
+		} else if !strings.HasPrefix(e, "TERM=") {
+			env = append(env, e)
+		}
+	}
+	if os.Getenv("LANG") == "" {
+		env = append(env, "LANG=en_US.UTF-8")
+	}
+
+	var cmd *exec.Cmd
+	var tmuxArgs []string
+	if socket != "" {
+		tmuxArgs = []string{"-S", socket}
+	}
+	if valid, _ := core.ValidateSessionName(arg, "session"); valid && sessionExists(tmuxArgs, arg, env) {
+		cmd = exec.Command("tmux", append(tmuxArgs, "attach-session", "-t", "="+arg)...)
+	} else {
+		cmd = exec.Command("bash", "-l")
+	}
+	cmd.Env = env
+	if dir := core.GetWorkDir(); core.FileExists(dir) {
+		cmd.Dir = dir
+	}
+	return cmd
+}
+
+// sessionExists reports whether a tmux session exists
+func sessionExists(tmuxArgs []string, name string, env []string) bool {
+	cmd := exec.Command("tmux", append(tmuxArgs, "has-session", "-t", "="+name)...)
+	cmd.Env = env
+	return cmd.Run() == nil
+}
+
+// serveWebSocket runs one terminal for a WebSocket client until either side closes
+func (nt *NativeTerminal) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true // Allow all origins (CORS is handled by middleware)
+		},
+		Subprotocols:    []string{"tty"},
+		ReadBufferSize:  4096,
+		WriteBufferSize: nativeReadBufferSize + 1,
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade terminal WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// The client opens with its terminal size
+	conn.SetReadDeadline(time.Now().Add(nativeHandshakeTimeout))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	var handshake ttydHandshake
+	if len(message) == 0 || message[0] != ttydJSON || json.Unmarshal(message, &handshake) != nil {
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "expected terminal size"))
+		return
+	}
+	if handshake.Columns == 0 || handshake.Rows == 0 {
+		handshake.Columns, handshake.Rows = 80, 24
+	}
+
+	arg := r.URL.Query().Get("arg")
+	cmd := nt.command(arg)
+	pty, err := startPTY(cmd, handshake.Columns, handshake.Rows)
+	if err != nil {
+		log.Printf("Failed to start terminal: %v", err)
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "failed to start terminal"))
+		return
+	}
+
+	c := &nativeClient{conn: conn, pty: pty, cmd: cmd}
+	nt.mu.Lock()
+	if nt.stopped {
+		nt.mu.Unlock()
+		c.close()
+		return
+	}
+	nt.clients[c] = struct{}{}
+	nt.mu.Unlock()
+
+	defer func() {
+		nt.mu.Lock()
+		delete(nt.clients, c)
+		nt.mu.Unlock()
+		c.close()
+	}()
+
+	hostname, _ := os.Hostname()
+	title := fmt.Sprintf("%s (%s)", strings.Join(cmd.Args, " "), hostname)
+	c.send(ttydSetWindowTitle, []byte(title))
+	c.send(ttydSetPreferences, []byte("{}"))
+
+	done := make(chan struct{})
+	go func() {
+		c.pumpOutput()
+		close(done)
+	}()
+	go c.pumpInput()
+
+	<-done
+}
+
+// send writes one ttyd message
+func (c *nativeClient) send(messageType byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(websocket.BinaryMessage, append([]byte{messageType}, payload...))
+}
+
+// pumpOutput copies pty output to the client until the pty closes
+func (c *nativeClient) pumpOutput() {
+	buf := make([]byte, nativeReadBufferSize)
+	for {
+		c.waitResumed()
+		n, err := c.pty.Read(buf)
+		if n > 0 {
+			if c.send(ttydOutput, buf[:n]) != nil {
+				return
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				// EIO once the process exits and the last slave fd closes
+				c.conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			}
+			return
+		}
+	}
+}
+
+// pumpInput applies client messages until the connection closes, then closes the pty
+func (c *nativeClient) pumpInput() {
+	defer c.pty.Close()
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			c.setPaused(false)
+			return
+		}
+		if len(message) == 0 {
+			continue
+		}
+
+		switch message[0] {
+		case ttydInput:
+			if _, err := c.pty.Write(message[1:]); err != nil {
+				return
+			}
+		case ttydResize:
+			var size ttydResizeMessage
+			if json.Unmarshal(message[1:], &size) == nil && size.Columns > 0 && size.Rows > 0 {
+				setPTYSize(c.pty, size.Columns, size.Rows)
+			}
+		case ttydPause:
+			c.setPaused(true)
+		case ttydResume:
+			c.setPaused(false)
+		}
+	}
+}
+
+// setPaused pauses or resumes output (ttyd flow control)
+func (c *nativeClient) setPaused(paused bool) {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	if paused == c.paused {
+		return
+	}
+	c.paused = paused
+	if paused {
+		c.resume = make(chan struct{})
+	} else {
+		close(c.resume)
+	}
+}
+
+// waitResumed blocks while output is paused
+func (c *nativeClient) waitResumed() {
+	c.pauseMu.Lock()
+	paused, resume := c.paused, c.resume
+	c.pauseMu.Unlock()
+	if paused {
+		<-resume
+	}
+}
+
+// close ends the client's process: closing the pty hangs it up, and it is killed if it lingers
+func (c *nativeClient) close() {
+	c.conn.Close()
+	c.pty.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		c.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(nativeExitTimeout):
+		c.cmd.Process.Kill()
+		<-exited
+	}
+}
@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// readTerminalUntil reads ttyd output messages until the collected output contains want
+func readTerminalUntil(t *testing.T, conn *websocket.Conn, want string) string {
+	t.Helper()
+	var output strings.Builder
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for !strings.Contains(output.String(), want) {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("waiting for %q, got %q: %v", want, output.String(), err)
+		}
+		if len(message) > 0 && message[0] == ttydOutput {
+			output.Write(message[1:])
+		}
+	}
+	return output.String()
+}
+
+func TestNativeTerminal_Protocol(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("native terminal backend requires Linux")
+	}
+
+	nt := NewNativeTerminal()
+	var gotArg string
+	nt.command = func(arg string) *exec.Cmd {
+		gotArg = arg
+		return exec.Command("sh", "-c", "stty size; read line; stty size; echo got:$line")
+	}
+	mux := http.NewServeMux()
+	nt.RegisterRoutes(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	defer nt.Stop()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"tty"}}
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/terminal/ws?arg=demo"
+	conn, resp, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	if resp.Header.Get("Sec-WebSocket-Protocol") != "tty" {
+		t.Errorf("subprotocol = %q, want tty", resp.Header.Get("Sec-WebSocket-Protocol"))
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, []byte(`{"AuthToken":"","columns":100,"rows":30}`))
+	readTerminalUntil(t, conn, "30 100")
+	if gotArg != "demo" {
+		t.Errorf("command arg = %q, want demo", gotArg)
+	}
+	if nt.ClientCount() != 1 {
+		t.Errorf("ClientCount() = %d, want 1", nt.ClientCount())
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, []byte(`1{"columns":120,"rows":40}`))
+	conn.WriteMessage(websocket.BinaryMessage, []byte("0hello\r"))
+	output := readTerminalUntil(t, conn, "got:hello")
+	if !strings.Contains(output, "40 120") {
+		t.Errorf("output after resize = %q, want new size 40 120", output)
+	}
+
+	// The connection closes when the process exits
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for nt.ClientCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if nt.ClientCount() != 0 {
+		t.Errorf("ClientCount() = %d after exit, want 0", nt.ClientCount())
+	}
+}
+
+func TestNativeTerminal_RejectsMissingHandshake(t *testing.T) {
+	nt := NewNativeTerminal()
+	nt.command = func(arg string) *exec.Cmd {
+		t.Error("command should not start without a handshake")
+		return exec.Command("true")
+	}
+	server := httptest.NewServer(nt.Handler())
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/terminal/ws", nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	conn.WriteMessage(websocket.BinaryMessage, []byte("0ls\r"))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Errorf("expected policy violation close, got %v", err)
+	}
+}
+
+func TestNativeTerminal_Pages(t *testing.T) {
+	nt := NewNativeTerminal()
+	handler := nt.Handler()
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/terminal/", http.StatusOK, "new WebSocket"},
+		{"/terminal/token", http.StatusOK, `"token"`},
+		{"/terminal/missing.js", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("body does not contain %q", tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !linux
+
+// Package proxy provides reverse proxy functionality for ttyd
+package proxy
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// errNoPTY is returned where the native terminal backend is not supported
+var errNoPTY = errors.New("the native terminal backend is only supported on Linux")
+
+// setPTYSize sets the terminal size of a pty
+func setPTYSize(pty *os.File, cols, rows uint16) error {
+	return errNoPTY
+}
+
+// startPTY starts cmd as the session leader of a new pty and returns the pty's master side
+func startPTY(cmd *exec.Cmd, cols, rows uint16) (*os.File, error) {
+	return nil, errNoPTY
+}
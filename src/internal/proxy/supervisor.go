@@ -0,0 +1,271 @@
+// Package proxy provides reverse proxy functionality for ttyd
+package proxy
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Supervisor states
+const (
+	StateStarting = "starting" // Launched, waiting for it to answer on its port
+	StateRunning  = "running"
+	StateBackoff  = "backoff" // Exited, waiting to restart
+	StateStopped  = "stopped"
+)
+
+const (
+	// readyTimeout is how long a started process has to answer on its port
+	readyTimeout = 5 * time.Second
+	// readyPollInterval is the delay between readiness probes
+	readyPollInterval = 50 * time.Millisecond
+	// minRestartBackoff and maxRestartBackoff bound the delay before a restart, doubling per crash
+	minRestartBackoff = time.Second
+	maxRestartBackoff = 30 * time.Second
+	// stableUptime is how long a process must run for its next crash to restart without delay build-up
+	stableUptime = time.Minute
+	// stopTimeout is how long a process gets to exit after an interrupt before it is killed
+	stopTimeout = 5 * time.Second
+)
+
+// ProcessStatus describes a supervised process
+type ProcessStatus struct {
+	State       string `json:"state"`
+	PID         int    `json:"pid,omitempty"`
+	Port        int    `json:"port"`
+	Since       string `json:"since,omitempty"`       // When the current state began
+	Restarts    int    `json:"restarts"`              // Launches after the first
+	Crashes     int    `json:"crashes"`               // Unexpected exits and failed starts
+	LastExit    string `json:"lastExit,omitempty"`    // Why the process last ended
+	LastExitAt  string `json:"lastExitAt,omitempty"`  // When it last ended
+	NextRestart string `json:"nextRestart,omitempty"` // When a backoff ends
+}
+
+// supervisor keeps a process serving HTTP on a port running: it launches it,
+// probes the port until it answers, and restarts it with backoff when it exits
+type supervisor struct {
+	name   string
+	port   int
+	newCmd func() *exec.Cmd
+	probe  func(port int) bool
+
+	mu     sync.Mutex
+	status ProcessStatus
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// newSupervisor creates a supervisor for the process newCmd builds
+func newSupervisor(name string, port int, newCmd func() *exec.Cmd) *supervisor {
+	return &supervisor{
+		name:   name,
+		port:   port,
+		newCmd: newCmd,
+		probe:  probeHTTP,
+		status: ProcessStatus{State: StateStopped, Port: port},
+	}
+}
+
+// probeHTTP reports whether something answers HTTP requests on a local port
+func probeHTTP(port int) bool {
+	client := http.Client{Timeout: time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/", port))
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
+
+// Start launches the process and supervises it until Stop
+// It returns once the first launch is ready, or with the reason it is not;
+// in that case supervision continues and keeps retrying
+func (s *supervisor) Start() error {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	firstReady := make(chan error, 1)
+	go s.run(firstReady)
+	return <-firstReady
+}
+
+// Stop ends supervision and the process
+func (s *supervisor) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop = nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// Status returns a snapshot of the supervisor's state
+func (s *supervisor) Status() ProcessStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// IsRunning returns whether the process is up and answering
+func (s *supervisor) IsRunning() bool {
+	return s.Status().State == StateRunning
+}
+
+// setState records a state change
+func (s *supervisor) setState(state string, pid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.State = state
+	s.status.PID = pid
+	s.status.Since = time.Now().UTC().Format(time.RFC3339)
+	s.status.NextRestart = ""
+}
+
+// recordExit records an unexpected exit or failed start
+func (s *supervisor) recordExit(reason string) {
+	log.Printf("%s %s", s.name, reason)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Crashes++
+	s.status.LastExit = reason
+	s.status.LastExitAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+// run launches and relaunches the process until stop closes
+func (s *supervisor) run(firstReady chan<- error) {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.mu.Unlock()
+	defer close(done)
+	defer s.setState(StateStopped, 0)
+
+	backoff := minRestartBackoff
+	for launches := 0; ; launches++ {
+		if launches > 0 {
+			s.mu.Lock()
+			s.status.Restarts++
+			s.mu.Unlock()
+		}
+
+		started := time.Now()
+		err := s.runOnce(stop, firstReady)
+		firstReady = nil
+		if err == nil {
+			return // Stopped
+		}
+		s.recordExit(err.Error())
+
+		// A process that stayed up for a while restarts quickly again
+		if time.Since(started) >= stableUptime {
+			backoff = minRestartBackoff
+		}
+		s.setState(StateBackoff, 0)
+		s.mu.Lock()
+		s.status.NextRestart = time.Now().Add(backoff).UTC().Format(time.RFC3339)
+		s.mu.Unlock()
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRestartBackoff)
+	}
+}
+
+// runOnce launches the process and waits for it to exit or for stop
+// It returns nil when stopped, otherwise why the process is gone. The first
+// launch reports its readiness on firstReady
+func (s *supervisor) runOnce(stop <-chan struct{}, firstReady chan<- error) error {
+	ready := func(err error) {
+		if firstReady != nil {
+			firstReady <- err
+			firstReady = nil
+		}
+	}
+
+	// Kill anything left on our port (e.g. a stale instance) so the new process can bind it
+	exec.Command("fuser", "-k", fmt.Sprintf("%d/tcp", s.port)).Run()
+
+	cmd := s.newCmd()
+	if err := cmd.Start(); err != nil {
+		err = fmt.Errorf("failed to start: %w", err)
+		ready(err)
+		return err
+	}
+	s.setState(StateStarting, cmd.Process.Pid)
+	log.Printf("Started %s on port %d (pid %d)", s.name, s.port, cmd.Process.Pid)
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	// Probe until the port answers, the process dies, or it takes too long
+	deadline := time.After(readyTimeout)
+	for !s.probe(s.port) {
+		select {
+		case <-stop:
+			ready(fmt.Errorf("stopped before ready"))
+			return s.terminate(cmd, exited)
+		case err := <-exited:
+			err = exitError(err)
+			ready(err)
+			return err
+		case <-deadline:
+			cmd.Process.Kill()
+			<-exited
+			err := fmt.Errorf("not ready on port %d after %v", s.port, readyTimeout)
+			ready(err)
+			return err
+		case <-time.After(readyPollInterval):
+		}
+	}
+	s.setState(StateRunning, cmd.Process.Pid)
+	ready(nil)
+
+	select {
+	case <-stop:
+		return s.terminate(cmd, exited)
+	case err := <-exited:
+		return exitError(err)
+	}
+}
+
+// terminate interrupts the process, killing it if it does not exit in time
+func (s *supervisor) terminate(cmd *exec.Cmd, exited <-chan error) error {
+	log.Printf("Stopping %s...", s.name)
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		cmd.Process.Kill()
+	}
+	select {
+	case <-exited:
+	case <-time.After(stopTimeout):
+		cmd.Process.Kill()
+		<-exited
+	}
+	log.Printf("%s stopped", s.name)
+	return nil
+}
+
+// exitError describes how a process exited
+func exitError(err error) error {
+	if err != nil {
+		return fmt.Errorf("exited with error: %w", err)
+	}
+	return fmt.Errorf("exited unexpectedly")
+}
@@ -0,0 +1,86 @@
+package proxy
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForState polls a supervisor until it reaches state
+func waitForState(t *testing.T, s *supervisor, state string, timeout time.Duration) ProcessStatus {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		status := s.Status()
+		if status.State == state {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("state = %q, want %q (status %+v)", status.State, state, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSupervisor_StartStop(t *testing.T) {
+	s := newSupervisor("sleeper", 0, func() *exec.Cmd { return exec.Command("sleep", "30") })
+	s.probe = func(port int) bool { return true }
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	status := s.Status()
+	if status.State != StateRunning || status.PID == 0 {
+		t.Errorf("status after Start = %+v, want running with a pid", status)
+	}
+	if !s.IsRunning() {
+		t.Error("IsRunning() = false after Start")
+	}
+
+	s.Stop()
+	status = s.Status()
+	if status.State != StateStopped || status.Crashes != 0 {
+		t.Errorf("status after Stop = %+v, want stopped without crashes", status)
+	}
+}
+
+func TestSupervisor_RestartsAfterCrash(t *testing.T) {
+	s := newSupervisor("sleeper", 0, func() *exec.Cmd { return exec.Command("sleep", "30") })
+	s.probe = func(port int) bool { return true }
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer s.Stop()
+
+	first := s.Status().PID
+	exec.Command("kill", "-9", strconv.Itoa(first)).Run()
+
+	status := waitForState(t, s, StateBackoff, 2*time.Second)
+	if status.Crashes != 1 || status.LastExit == "" || status.NextRestart == "" {
+		t.Errorf("status after crash = %+v, want one crash with its reason and next restart", status)
+	}
+
+	status = waitForState(t, s, StateRunning, 3*time.Second)
+	if status.Restarts != 1 || status.PID == first {
+		t.Errorf("status after restart = %+v, want a new pid and one restart", status)
+	}
+}
+
+func TestSupervisor_NotReady(t *testing.T) {
+	s := newSupervisor("missing", 0, func() *exec.Cmd { return exec.Command("/nonexistent/ttyd") })
+
+	if err := s.Start(); err == nil {
+		t.Error("Start() should fail when the process cannot start")
+	}
+	status := waitForState(t, s, StateBackoff, time.Second)
+	if status.Crashes != 1 || !strings.Contains(status.LastExit, "failed to start") {
+		t.Errorf("status = %+v, want a failed start", status)
+	}
+
+	s.Stop()
+	if state := s.Status().State; state != StateStopped {
+		t.Errorf("state after Stop = %q, want stopped", state)
+	}
+}
@@ -2,7 +2,6 @@
 package proxy
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,7 +10,6 @@ import (
 	"os"
 	"os/exec"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/chrote/server/internal/core"
@@ -30,10 +28,8 @@ var wsUpgrader = websocket.Upgrader{
 // TerminalProxy manages ttyd process and proxies requests
 type TerminalProxy struct {
 	ttydPort     int
-	ttydCmd      *exec.Cmd
 	proxy        *httputil.ReverseProxy
-	mu           sync.Mutex
-	running      bool
+	sup          *supervisor
 	launchScript string
 }
 
@@ -60,31 +56,44 @@ func NewTerminalProxy(ttydPort int) *TerminalProxy {
 		http.Error(w, "Terminal not available", http.StatusBadGateway)
 	}
 
-	return &TerminalProxy{
+	tp := &TerminalProxy{
 		ttydPort:     ttydPort,
 		proxy:        proxy,
 		launchScript: core.GetLaunchScript(),
 	}
+	tp.sup = newSupervisor("ttyd", ttydPort, tp.ttydCommand)
+	return tp
 }
 
-// Start starts the ttyd process
+// Start starts ttyd under supervision: it is restarted with backoff whenever it exits
+// An error means the first launch is not ready; supervision keeps retrying
 func (tp *TerminalProxy) Start() error {
-	tp.mu.Lock()
-	defer tp.mu.Unlock()
-
-	if tp.running {
-		return nil
+	if _, err := exec.LookPath("ttyd"); err != nil {
+		return fmt.Errorf("failed to start ttyd: %w", err)
 	}
+	return tp.sup.Start()
+}
 
-	// Kill any existing process on our port to prevent stale ttyd conflicts
-	// This handles cases where a previous ttyd instance wasn't cleaned up properly
-	killCmd := exec.Command("fuser", "-k", fmt.Sprintf("%d/tcp", tp.ttydPort))
-	killCmd.Run() // Ignore errors - port may not be in use
-	time.Sleep(100 * time.Millisecond)
+// Stop stops ttyd and its supervision
+func (tp *TerminalProxy) Stop() error {
+	tp.sup.Stop()
+	return nil
+}
+
+// IsRunning returns whether ttyd is running and ready
+func (tp *TerminalProxy) IsRunning() bool {
+	return tp.sup.IsRunning()
+}
+
+// Status returns the state of the supervised ttyd process
+func (tp *TerminalProxy) Status() ProcessStatus {
+	return tp.sup.Status()
+}
 
-	// Build ttyd command
+// ttydCommand builds the ttyd command line
+func (tp *TerminalProxy) ttydCommand() *exec.Cmd {
 	// ttyd -p PORT -W -a terminal-launch.sh
-	tp.ttydCmd = exec.Command(
+	cmd := exec.Command(
 		"ttyd",
 		"-p", fmt.Sprintf("%d", tp.ttydPort),
 		"-W", // WebSocket only mode (for better performance)
@@ -93,82 +102,12 @@ func (tp *TerminalProxy) Start() error {
 	)
 
 	// Set environment with TMUX_TMPDIR
-	tp.ttydCmd.Env = core.GetTmuxEnv()
+	cmd.Env = core.GetTmuxEnv()
 
 	// Pipe stdout/stderr for debugging
-	tp.ttydCmd.Stdout = os.Stdout
-	tp.ttydCmd.Stderr = os.Stderr
-
-	if err := tp.ttydCmd.Start(); err != nil {
-		return fmt.Errorf("failed to start ttyd: %w", err)
-	}
-
-	tp.running = true
-	log.Printf("Started ttyd on port %d", tp.ttydPort)
-
-	// Monitor the process
-	go func() {
-		err := tp.ttydCmd.Wait()
-		tp.mu.Lock()
-		tp.running = false
-		tp.mu.Unlock()
-		if err != nil {
-			log.Printf("ttyd exited with error: %v", err)
-		} else {
-			log.Printf("ttyd exited normally")
-		}
-	}()
-
-	// Wait a moment for ttyd to start
-	time.Sleep(500 * time.Millisecond)
-
-	return nil
-}
-
-// Stop stops the ttyd process
-func (tp *TerminalProxy) Stop() error {
-	tp.mu.Lock()
-	defer tp.mu.Unlock()
-
-	if !tp.running || tp.ttydCmd == nil || tp.ttydCmd.Process == nil {
-		return nil
-	}
-
-	log.Printf("Stopping ttyd...")
-
-	// Try graceful shutdown first
-	if err := tp.ttydCmd.Process.Signal(os.Interrupt); err != nil {
-		// Fall back to kill
-		tp.ttydCmd.Process.Kill()
-	}
-
-	// Wait for process to exit with timeout
-	done := make(chan error, 1)
-	go func() {
-		done <- tp.ttydCmd.Wait()
-	}()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	select {
-	case <-done:
-		// Process exited
-	case <-ctx.Done():
-		// Timeout - force kill
-		tp.ttydCmd.Process.Kill()
-	}
-
-	tp.running = false
-	log.Printf("ttyd stopped")
-	return nil
-}
-
-// IsRunning returns whether ttyd is running
-func (tp *TerminalProxy) IsRunning() bool {
-	tp.mu.Lock()
-	defer tp.mu.Unlock()
-	return tp.running
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
 }
 
 // Handler returns an http.Handler that proxies to ttyd